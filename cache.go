@@ -0,0 +1,339 @@
+package tcfg
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+)
+
+// cacheGeneration is bumped by changes that affect every ConfData, such as [RegisterFunc], so that caches filled
+// before the change are discarded.
+var cacheGeneration atomic.Uint64
+
+// valueCache holds resolved values and the parsed form of raw values for a [ConfData] with caching enabled.
+type valueCache struct {
+	sync.Mutex
+
+	// epoch is shared with the caches of the views of the same [ConfData] (see [ConfData.clearCache]).
+	epoch      *atomic.Uint64
+	generation cacheStamp
+
+	// vals maps a cache key to the alternatives and existence of a top-level key.
+	vals map[string]cacheEntry
+	// compiled maps a raw value to its parsed placeholders.
+	compiled map[string]*compiledValue
+	// templates maps a raw value to its parsed template in template mode.
+	templates map[string]*compiledTemplate
+}
+
+// cacheEntry is a resolved top-level key.
+type cacheEntry struct {
+	vals []string
+	ok   bool
+}
+
+// compiledValue is the parsed form of a raw value. parts alternates literal text, already unescaped, and
+// placeholder text such as ${KEY} or $[KEY]; keys lists each distinct placeholder once, in first-occurrence order.
+type compiledValue struct {
+	parts []string
+	keys  []string
+}
+
+// compiledTemplate is a parsed template together with the field references it makes.
+type compiledTemplate struct {
+	tmpl *template.Template
+	refs [][]string
+}
+
+// cacheStamp identifies the state a cache was filled in: the global generation and the epoch of its ConfData.
+type cacheStamp struct {
+	global uint64
+	local  uint64
+}
+
+// newValueCache returns an empty cache for the current generation of epoch.
+func newValueCache(epoch *atomic.Uint64) *valueCache {
+	return &valueCache{
+		epoch:      epoch,
+		generation: cacheStamp{global: cacheGeneration.Load(), local: epoch.Load()},
+
+		vals:      make(map[string]cacheEntry),
+		compiled:  make(map[string]*compiledValue),
+		templates: make(map[string]*compiledTemplate),
+	}
+}
+
+// checkGeneration empties p when a global change, or a change to its ConfData or one of its views, happened since
+// it was filled. The caller holds the lock.
+func (p *valueCache) checkGeneration() {
+	generation := cacheStamp{global: cacheGeneration.Load(), local: p.epoch.Load()}
+
+	if p.generation != generation {
+		p.generation = generation
+
+		p.vals = make(map[string]cacheEntry)
+		p.compiled = make(map[string]*compiledValue)
+		p.templates = make(map[string]*compiledTemplate)
+	}
+}
+
+// WithCache enables or disables caching of resolved values (see [ConfData.SetCache]).
+func WithCache(enabled bool) Option {
+	return func(p *ConfData) {
+		if enabled {
+			p.cache = newValueCache(p.sharedEpoch())
+		} else {
+			p.cache = nil
+		}
+	}
+}
+
+// SetCache enables or disables caching. With caching enabled each key is resolved once: later reads return the
+// cached result without consulting the environment or INI data again, and each raw value and template is parsed
+// once. Changing a setting of p, such as its key prefix, or calling [RegisterFunc] discards the cache; call [ConfData.Refresh] to
+// pick up changed environment variables. Errors are not cached.
+func (p *ConfData) SetCache(enabled bool) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	if enabled {
+		p.cache = newValueCache(p.sharedEpoch())
+	} else {
+		p.cache = nil
+	}
+
+	p.mutex.Unlock()
+}
+
+// Refresh re-reads an environment snapshot (see [EnvData.Refresh]) and discards every cached value, so the next
+// read resolves keys again.
+func (p *ConfData) Refresh() {
+	if p == nil {
+		return
+	}
+
+	p.mutex.RLock()
+	envData := p.envData
+	p.mutex.RUnlock()
+
+	if envData != nil {
+		envData.Refresh()
+	}
+
+	p.clearCache()
+}
+
+// clearCache discards every cached value of p and of the views created from it by [ConfData.WithPrefix] or
+// [ConfData.Sub], and of the ConfData p is a view of, since they share their layers.
+func (p *ConfData) clearCache() {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.sharedEpoch().Add(1)
+
+	p.mutex.Unlock()
+}
+
+// sharedEpoch returns the epoch p shares with its views, creating it on first use. The caller holds the write lock,
+// or is configuring p in [New].
+func (p *ConfData) sharedEpoch() *atomic.Uint64 {
+	if p.epoch == nil {
+		p.epoch = new(atomic.Uint64)
+	}
+
+	return p.epoch
+}
+
+// getCache returns the cache of p, or nil when caching is disabled.
+func (p *ConfData) getCache() *valueCache {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.cache
+}
+
+// cached returns the result of resolveFn for cacheKey, calling it only on a cache miss when caching is enabled.
+// The returned slice is a copy the caller may modify.
+func (p *ConfData) cached(cacheKey string, resolveFn func() ([]string, bool, error)) ([]string, bool, error) {
+	cache := p.getCache()
+	if cache == nil {
+		return resolveFn()
+	}
+
+	cache.Lock()
+	cache.checkGeneration()
+	entry, ok := cache.vals[cacheKey]
+	generation := cache.generation
+	cache.Unlock()
+
+	if ok {
+		return append([]string(nil), entry.vals...), entry.ok, nil
+	}
+
+	vals, ok, err := resolveFn()
+	if err != nil {
+		return nil, ok, err
+	}
+
+	cache.Lock()
+	if cache.generation == generation {
+		cache.vals[cacheKey] = cacheEntry{vals: append([]string(nil), vals...), ok: ok}
+	}
+	cache.Unlock()
+
+	return vals, ok, nil
+}
+
+// compileValue returns the parsed form of val, from the cache when caching is enabled.
+func (p *ConfData) compileValue(val string) *compiledValue {
+	cache := p.getCache()
+	if cache == nil {
+		return parseValue(val)
+	}
+
+	cache.Lock()
+	cache.checkGeneration()
+	compiled, ok := cache.compiled[val]
+	cache.Unlock()
+
+	if ok {
+		return compiled
+	}
+
+	compiled = parseValue(val)
+
+	cache.Lock()
+	cache.compiled[val] = compiled
+	cache.Unlock()
+
+	return compiled
+}
+
+// parseValue scans val for ${...} and $[...] placeholders. A ${ placeholder extends to its matching brace, and
+// a placeholder overlapping an earlier one or preceded by an escaping $ is kept as literal text.
+func parseValue(val string) *compiledValue {
+	retMatches := ValStringKeyMatchReg.FindAllStringIndex(val, -1)
+	for _, retMatch := range retMatches {
+		retMatch[1] = placeholderEnd(val, retMatch[0], retMatch[1])
+	}
+
+	retMatches = append(retMatches, ValStringsKeyMatchReg.FindAllStringIndex(val, -1)...)
+
+	sort.Slice(retMatches, func(i, j int) bool {
+		return retMatches[i][0] < retMatches[j][0]
+	})
+
+	compiled := &compiledValue{
+		parts: make([]string, 0, 2*len(retMatches)+1),
+		keys:  make([]string, 0, len(retMatches)),
+	}
+
+	matchKeysMap := make(map[string]bool)
+
+	literal := ""
+	startIndex := 0
+
+	for _, retMatch := range retMatches {
+		tmpStartIndex := retMatch[0]
+		tmpEndIndex := retMatch[1]
+
+		if tmpStartIndex < startIndex {
+			continue
+		}
+
+		literal += val[startIndex:tmpStartIndex]
+		startIndex = tmpEndIndex
+
+		if tmpStartIndex > 0 && val[tmpStartIndex-1] == '$' {
+			literal += val[tmpStartIndex:tmpEndIndex]
+
+			continue
+		}
+
+		matchKey := val[tmpStartIndex:tmpEndIndex]
+
+		compiled.parts = append(compiled.parts, unescapeValue(literal), matchKey)
+		literal = ""
+
+		if !matchKeysMap[matchKey] {
+			matchKeysMap[matchKey] = true
+
+			compiled.keys = append(compiled.keys, matchKey)
+		}
+	}
+
+	literal += val[startIndex:]
+	compiled.parts = append(compiled.parts, unescapeValue(literal))
+
+	return compiled
+}
+
+// compileTemplate returns val parsed as a template together with its field references, from the cache when
+// caching is enabled. The template's functions are bound to chain; a cached template is cloned and rebound.
+func (p *ConfData) compileTemplate(val string, chain []string) (*compiledTemplate, error) {
+	cache := p.getCache()
+	if cache == nil {
+		return parseTemplate(val, chain, p.templateFuncs(chain))
+	}
+
+	cache.Lock()
+	cache.checkGeneration()
+	compiled, ok := cache.templates[val]
+	cache.Unlock()
+
+	if !ok {
+		var err error
+
+		compiled, err = parseTemplate(val, chain, p.templateFuncs(nil))
+		if err != nil {
+			return nil, err
+		}
+
+		cache.Lock()
+		cache.templates[val] = compiled
+		cache.Unlock()
+	}
+
+	tmpl, err := compiled.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return &compiledTemplate{tmpl: tmpl.Funcs(p.templateFuncs(chain)), refs: compiled.refs}, nil
+}
+
+// parseTemplate parses val as a template using funcMap and collects its field references.
+func parseTemplate(val string, chain []string, funcMap template.FuncMap) (*compiledTemplate, error) {
+	name := ""
+	if len(chain) > 0 {
+		name = chain[len(chain)-1]
+	}
+
+	tmpl, err := template.New(name).Funcs(funcMap).Parse(val)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &compiledTemplate{tmpl: tmpl}
+
+	if tmpl.Tree != nil {
+		walkTemplateNode(tmpl.Tree.Root, []string{}, func(idents []string) {
+			compiled.refs = append(compiled.refs, idents)
+		})
+	}
+
+	return compiled, nil
+}
+
+// cacheKey returns the key under which the result for key is cached; kind tells string and list results apart.
+func cacheKey(kind string, key string) string {
+	return kind + "\x00" + strings.ToUpper(strings.TrimSpace(key))
+}
@@ -0,0 +1,248 @@
+package tcfg
+
+import (
+	"testing"
+)
+
+// newBenchConfData returns a ConfData whose URL key expands several nested references and a list, with the
+// given cache and template settings.
+func newBenchConfData(b *testing.B, cache bool, templateMode bool) *ConfData {
+	b.Helper()
+
+	configs := []*Config{
+		{Key: "HOST", Value: "db.internal"},
+		{Key: "PORT", Value: "5432"},
+		{Key: "NAME", Value: "orders"},
+		{Key: "HOSTS", Value: "a,b,c"},
+		{Key: "DSN", Value: "postgres://${HOST}:${PORT}/${NAME}"},
+		{Key: "URL", Value: "${DSN}?replicas=$[HOSTS]"},
+		{Key: "TMPL", Value: `{{ .HOST }}:{{ .PORT }}/{{ upper "NAME" }}`},
+	}
+
+	iniData, err := (&IniMgr{}).ParseConfig(configs)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	confData, err := New(WithIniData(iniData), WithEnvData(NewEnvDataFromMap(nil)), WithCache(cache),
+		WithTemplateMode(templateMode))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return confData
+}
+
+func benchmarkString(b *testing.B, confData *ConfData, key string) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_, err := confData.String(key)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	benchmarkString(b, newBenchConfData(b, false, false), "URL")
+}
+
+func BenchmarkStringCached(b *testing.B) {
+	benchmarkString(b, newBenchConfData(b, true, false), "URL")
+}
+
+func BenchmarkStringTemplate(b *testing.B) {
+	benchmarkString(b, newBenchConfData(b, false, true), "TMPL")
+}
+
+func BenchmarkStringTemplateCached(b *testing.B) {
+	benchmarkString(b, newBenchConfData(b, true, true), "TMPL")
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		env    map[string]string
+		key    string
+		change func(t *testing.T, confData *ConfData)
+
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name: "Refresh",
+			src:  "HOST = ini\n",
+			key:  "TCFG_CACHE_HOST",
+			change: func(t *testing.T, confData *ConfData) {
+				t.Setenv("TCFG_CACHE_HOST", "b")
+				confData.Refresh()
+			},
+			wantBefore: "a",
+			wantAfter:  "b",
+		},
+		{
+			name: "EnvData.Refresh",
+			src:  "HOST = ini\n",
+			key:  "TCFG_CACHE_HOST",
+			change: func(t *testing.T, confData *ConfData) {
+				t.Setenv("TCFG_CACHE_HOST", "b")
+				confData.envData.Refresh()
+			},
+			wantBefore: "a",
+			wantAfter:  "b",
+		},
+		{
+			name: "SetListSeparator",
+			src:  "HOSTS = a;b\nV = h-$[HOSTS]\n",
+			key:  "V",
+			change: func(t *testing.T, confData *ConfData) {
+				confData.SetListSeparator(";")
+			},
+			wantBefore: "h-a;b",
+			wantAfter:  "h-a;h-b",
+		},
+		{
+			name: "SetKeyPrefix",
+			src:  "HOST = a\nAPP_HOST = b\n",
+			key:  "HOST",
+			change: func(t *testing.T, confData *ConfData) {
+				confData.SetKeyPrefix("APP_")
+			},
+			wantBefore: "a",
+			wantAfter:  "b",
+		},
+		{
+			name: "SetEnvMapper",
+			src:  "[DB]\nHOST = ini\n",
+			env:  map[string]string{"DB_HOST": "env"},
+			key:  "DB::HOST",
+			change: func(t *testing.T, confData *ConfData) {
+				confData.SetEnvMapper(EnvMapperSectionKey)
+			},
+			wantBefore: "ini",
+			wantAfter:  "env",
+		},
+		{
+			name: "EnvData.SetMapper",
+			src:  "[DB]\nHOST = ini\n",
+			env:  map[string]string{"DB_HOST": "env"},
+			key:  "DB::HOST",
+			change: func(t *testing.T, confData *ConfData) {
+				confData.envData.SetMapper(EnvMapperSectionKey)
+			},
+			wantBefore: "ini",
+			wantAfter:  "env",
+		},
+		{
+			name: "SetScopes",
+			src:  "APP_NAME = eu\nREGION = us\nUS_HOST = region\nEU_HOST = app\n",
+			key:  "EU_US_HOST",
+			change: func(t *testing.T, confData *ConfData) {
+				confData.SetScopes("APP_NAME", "REGION")
+			},
+			wantBefore: "region",
+			wantAfter:  "app",
+		},
+		{
+			name: "SetTemplateMode",
+			src:  "HOST = h\nV = {{ .HOST }}\n",
+			key:  "V",
+			change: func(t *testing.T, confData *ConfData) {
+				confData.SetTemplateMode(true)
+			},
+			wantBefore: "{{ .HOST }}",
+			wantAfter:  "h",
+		},
+		{
+			name: "SetProfiles",
+			src:  "[DB]\nHOST = a\n[DB@PROD]\nHOST = b\n",
+			key:  "DB::HOST",
+			change: func(t *testing.T, confData *ConfData) {
+				confData.SetProfiles("prod")
+			},
+			wantBefore: "a",
+			wantAfter:  "b",
+		},
+		{
+			name: "RegisterFunc",
+			src:  "V = ${test_cache}\n",
+			key:  "V",
+			change: func(t *testing.T, confData *ConfData) {
+				err := RegisterFunc("test_cache", func(ctx *FuncContext, args []string) (string, error) {
+					return "2", nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantBefore: "1",
+			wantAfter:  "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TCFG_CACHE_HOST", "a")
+
+			err := RegisterFunc("test_cache", func(ctx *FuncContext, args []string) (string, error) {
+				return "1", nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := []Option{WithCache(true)}
+			if tt.env == nil {
+				opts = append(opts, WithEnvData(NewEnvSnapshot("TCFG_CACHE_")))
+			}
+
+			confData := newConfData(t, tt.src, tt.env, opts...)
+
+			val, err := confData.String(tt.key)
+			if err != nil || val != tt.wantBefore {
+				t.Fatalf("String(%q) = %q, %v, want %q", tt.key, val, err, tt.wantBefore)
+			}
+
+			tt.change(t, confData)
+
+			val, err = confData.String(tt.key)
+			if err != nil || val != tt.wantAfter {
+				t.Errorf("String(%q) after the change = %q, %v, want %q", tt.key, val, err, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestCacheInvalidationViews(t *testing.T) {
+	t.Setenv("TCFG_CACHE_HOST_DB", "a")
+
+	confData := newConfData(t, "[DB]\nTCFG_CACHE_HOST = ini\n", nil, WithCache(true),
+		WithEnvData(NewEnvSnapshot("TCFG_CACHE_")))
+
+	views := map[string]*ConfData{
+		"Sub":        confData.Sub("DB"),
+		"WithPrefix": confData.WithPrefix("TCFG_CACHE_").Sub("DB"),
+	}
+
+	keys := map[string]string{
+		"Sub":        "TCFG_CACHE_HOST",
+		"WithPrefix": "HOST",
+	}
+
+	for name, view := range views {
+		if val, err := view.String(keys[name]); err != nil || val != "a" {
+			t.Fatalf("%s: String() = %q, %v, want %q", name, val, err, "a")
+		}
+	}
+
+	t.Setenv("TCFG_CACHE_HOST_DB", "b")
+	confData.Refresh()
+
+	for name, view := range views {
+		if val, err := view.String(keys[name]); err != nil || val != "b" {
+			t.Errorf("%s: String() after Refresh = %q, %v, want %q", name, val, err, "b")
+		}
+	}
+}
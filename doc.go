@@ -12,7 +12,7 @@
 // # Resolution order
 //
 // For each logical key, environment variables take precedence over INI values. INI keys may use the
-// form SECTION::KEY; in the environment layer this maps to KEY_SECTION (see [EnvData]).
+// form SECTION::KEY; in the environment layer this maps to KEY_SECTION by default (see [EnvMapper]).
 //
 // # INI parsing without default loading
 //
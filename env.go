@@ -1,14 +1,291 @@
 package tcfg
 
 import (
+	"net/netip"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// EnvData reads configuration from process environment variables via [os.LookupEnv].
+// EnvData reads configuration from environment variables. The zero value is live: every access calls
+// [os.LookupEnv], so the result follows later calls to [os.Setenv]. [NewEnvSnapshot], [NewEnvDataFromList] and
+// [NewEnvDataFromMap] instead return an EnvData that serves a fixed set of variables until [EnvData.Refresh].
 type EnvData struct {
+	// snapshot holds the captured variables; nil in live mode.
+	snapshot map[string]string
+
+	// environ reports that the snapshot was taken from [os.Environ] and is re-read by Refresh.
+	environ bool
+	// prefix limits the keys a snapshot of [os.Environ] serves to those starting with it, plus names.
+	prefix string
+	names  []string
+
+	// mapper names the variable of a SECTION::KEY key; nil selects [EnvMapperKeySection].
+	mapper EnvMapper
+
+	sync.RWMutex
+}
+
+// EnvMapper names the environment variable that holds key KEY of INI section SECTION.
+type EnvMapper interface {
+	EnvName(section string, key string) string
+}
+
+// EnvMapperFunc adapts a function to [EnvMapper].
+type EnvMapperFunc func(section string, key string) string
+
+// EnvName implements [EnvMapper].
+func (f EnvMapperFunc) EnvName(section string, key string) string {
+	return f(section, key)
+}
+
+// Built-in [EnvMapper] strategies. Dots in section names, as in [A.B], become '_'.
+var (
+	// EnvMapperKeySection maps DB::HOST to HOST_DB. It is the default.
+	EnvMapperKeySection EnvMapper = EnvMapperFunc(func(section string, key string) string {
+		return key + "_" + envSection(section)
+	})
+
+	// EnvMapperSectionKey maps DB::HOST to DB_HOST.
+	EnvMapperSectionKey EnvMapper = EnvMapperFunc(func(section string, key string) string {
+		return envSection(section) + "_" + key
+	})
+
+	// EnvMapperSectionDoubleKey maps DB::HOST to DB__HOST, which keeps the section apart from keys containing '_'.
+	EnvMapperSectionDoubleKey EnvMapper = EnvMapperFunc(func(section string, key string) string {
+		return envSection(section) + "__" + key
+	})
+)
+
+// envSection returns section with dots replaced by '_' for use in a variable name.
+func envSection(section string) string {
+	return strings.ReplaceAll(section, ".", "_")
+}
+
+// NewEnvSnapshot returns an EnvData holding the variables of [os.Environ] at the time of the call. When prefix is
+// not empty only keys starting with prefix are served, together with [DefaultAppName] and names, such as the keys
+// of a custom scope chain. For SECTION::KEY the prefix applies to KEY, so with any [EnvMapper] the key
+// DB::MYAPP_HOST is served under prefix MYAPP_. Use [EnvData.Refresh] to capture the environment again.
+func NewEnvSnapshot(prefix string, names ...string) *EnvData {
+	envData := &EnvData{
+		environ: true,
+		prefix:  prefix,
+		names:   append([]string{DefaultAppName}, names...),
+	}
+
+	envData.snapshot = snapshotEnviron(os.Environ())
+
+	return envData
+}
+
+// NewEnvDataFromList returns an EnvData holding the NAME=VALUE entries of environ, in the format of [os.Environ].
+// Later entries win over earlier ones with the same name, and entries without '=' are ignored.
+func NewEnvDataFromList(environ []string) *EnvData {
+	return &EnvData{
+		snapshot: snapshotEnviron(environ),
+	}
+}
+
+// NewEnvDataFromMap returns an EnvData holding a copy of vals, keyed by variable name.
+func NewEnvDataFromMap(vals map[string]string) *EnvData {
+	snapshot := make(map[string]string, len(vals))
+
+	for key, val := range vals {
+		snapshot[key] = val
+	}
+
+	return &EnvData{
+		snapshot: snapshot,
+	}
+}
+
+// Refresh captures [os.Environ] again for an EnvData returned by [NewEnvSnapshot] and discards the cached values of
+// every [ConfData]. It does nothing for a live EnvData or one built from a list or map.
+func (p *EnvData) Refresh() {
+	if p == nil || !p.environ {
+		return
+	}
+
+	snapshot := snapshotEnviron(os.Environ())
+
+	p.Lock()
+
+	p.snapshot = snapshot
+
+	p.Unlock()
+
+	// Every ConfData reading p may have cached values of the previous snapshot.
+	cacheGeneration.Add(1)
+}
+
+// SetMapper sets the naming scheme used for SECTION::KEY keys. A nil mapper restores [EnvMapperKeySection]. Cached
+// values of every [ConfData] are discarded.
+func (p *EnvData) SetMapper(mapper EnvMapper) {
+	p.Lock()
+
+	p.mapper = mapper
+
+	p.Unlock()
+
+	cacheGeneration.Add(1)
+}
+
+// Mapper returns the naming scheme used for SECTION::KEY keys.
+func (p *EnvData) Mapper() EnvMapper {
+	p.RLock()
+	defer p.RUnlock()
+
+	if p.mapper == nil {
+		return EnvMapperKeySection
+	}
+
+	return p.mapper
+}
+
+// EnvName returns the name of the variable that holds key: key itself, or the name given by the mapper for
+// SECTION::KEY. Nested sections are passed to the mapper joined with '.', as in A.B.
+func (p *EnvData) EnvName(key string) string {
+	section, tmpKey, ok := cutKey(key)
+	if !ok {
+		return key
+	}
+
+	return p.Mapper().EnvName(section, tmpKey)
+}
+
+// IsSnapshot reports whether p serves a fixed set of variables rather than reading the live environment.
+func (p *EnvData) IsSnapshot() bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.snapshot != nil
+}
+
+// Keys returns, in sorted order, the keys held by the variables whose keys start with prefix. The mapper is
+// reversed for each of sections, so that with the default mapper and section DB the variable HOST_DB yields
+// DB::HOST; a variable matching no section yields its own name as a key of the default section. When several
+// sections match, the longest wins. In live mode the variables are read from [os.Environ].
+func (p *EnvData) Keys(prefix string, sections ...string) []string {
+	p.RLock()
+	snapshot := p.snapshot
+	p.RUnlock()
+
+	names := make([]string, 0, len(snapshot))
+
+	if snapshot == nil {
+		for _, entry := range os.Environ() {
+			if name, _, ok := strings.Cut(entry, "="); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+	} else {
+		for name := range snapshot {
+			names = append(names, name)
+		}
+	}
+
+	tmpSections := make([]string, 0, len(sections))
+
+	for _, section := range sections {
+		if section != "" && section != DefaultSection {
+			tmpSections = append(tmpSections, section)
+		}
+	}
+
+	slices.SortFunc(tmpSections, func(a string, b string) int {
+		return len(b) - len(a)
+	})
+
+	mapper := p.Mapper()
+
+	keysMap := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		key := ""
+
+		for _, section := range tmpSections {
+			if tmpKey, ok := reverseEnvName(mapper, section, name); ok && strings.HasPrefix(tmpKey, prefix) {
+				key = section + "::" + tmpKey
+
+				break
+			}
+		}
+
+		if key == "" && strings.HasPrefix(name, prefix) {
+			key = name
+		}
+
+		if key != "" && p.isServed(key) {
+			keysMap[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(keysMap))
+
+	for key := range keysMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// reverseEnvName returns the key of section that mapper names name, if any. It asks mapper for the name of a
+// marker key and matches name against the text around the marker, which suits mappers that embed the key unchanged.
+func reverseEnvName(mapper EnvMapper, section string, name string) (string, bool) {
+	const marker = "\x00"
+
+	before, after, ok := strings.Cut(mapper.EnvName(section, marker), marker)
+	if !ok || len(name) <= len(before)+len(after) {
+		return "", false
+	}
+
+	if !strings.HasPrefix(name, before) || !strings.HasSuffix(name, after) {
+		return "", false
+	}
+
+	return name[len(before) : len(name)-len(after)], true
+}
+
+// snapshotEnviron returns the NAME=VALUE entries of environ.
+func snapshotEnviron(environ []string) map[string]string {
+	snapshot := make(map[string]string, len(environ))
+
+	for _, entry := range environ {
+		key, val, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			continue
+		}
+
+		snapshot[key] = val
+	}
+
+	return snapshot
+}
+
+// isServed reports whether a snapshot taken by [NewEnvSnapshot] serves key: without a prefix every key is served,
+// otherwise keys whose name, without the section, starts with the prefix, and the names given to NewEnvSnapshot.
+func (p *EnvData) isServed(key string) bool {
+	if !p.environ || p.prefix == "" {
+		return true
+	}
+
+	if slices.Contains(p.names, key) {
+		return true
+	}
+
+	if _, tmpKey, ok := cutKey(key); ok {
+		key = tmpKey
+	}
+
+	return strings.HasPrefix(key, p.prefix)
 }
 
 // GetBool parses the environment value associated with key as a boolean.
@@ -20,6 +297,9 @@ func (p *EnvData) GetBool(key string) (bool, bool, error) {
 	}
 
 	ret, err := parseBool(val)
+	if err != nil {
+		err = p.parseError(key, val, "bool", err)
+	}
 
 	return ret, true, err
 }
@@ -49,6 +329,9 @@ func (p *EnvData) GetInt(key string) (int, bool, error) {
 	}
 
 	ret, err := strconv.Atoi(val)
+	if err != nil {
+		err = p.parseError(key, val, "int", err)
+	}
 
 	return ret, true, err
 }
@@ -78,6 +361,9 @@ func (p *EnvData) GetInt64(key string) (int64, bool, error) {
 	}
 
 	ret, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		err = p.parseError(key, val, "int64", err)
+	}
 
 	return ret, true, err
 }
@@ -107,6 +393,9 @@ func (p *EnvData) GetFloat(key string) (float64, bool, error) {
 	}
 
 	ret, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		err = p.parseError(key, val, "float64", err)
+	}
 
 	return ret, true, err
 }
@@ -136,6 +425,9 @@ func (p *EnvData) GetDuration(key string) (time.Duration, bool, error) {
 	}
 
 	ret, err := time.ParseDuration(val)
+	if err != nil {
+		err = p.parseError(key, val, "duration", err)
+	}
 
 	return ret, true, err
 }
@@ -157,7 +449,430 @@ func (p *EnvData) DefaultDuration(key string, defaultVal time.Duration) time.Dur
 	return val
 }
 
-// GetString returns the environment value for key after mapping SECTION::KEY to KEY_SECTION.
+// GetUint parses the value as a base-10 unsigned integer. The second return value is false if the variable is unset.
+func (p *EnvData) GetUint(key string) (uint, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	ret, err := strconv.ParseUint(val, 10, 0)
+	if err != nil {
+		err = p.parseError(key, val, "uint", err)
+	}
+
+	return uint(ret), true, err
+}
+
+// Uint returns the value from [EnvData.GetUint], or the zero value when the variable is unset.
+func (p *EnvData) Uint(key string) (uint, error) {
+	val, _, err := p.GetUint(key)
+
+	return val, err
+}
+
+// DefaultUint returns defaultVal when the variable is unset or [EnvData.GetUint] fails to parse.
+func (p *EnvData) DefaultUint(key string, defaultVal uint) uint {
+	val, ok, err := p.GetUint(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetUint64 parses the value as a base-10 unsigned 64-bit integer. The second return value is false if the variable is unset.
+func (p *EnvData) GetUint64(key string) (uint64, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	ret, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		err = p.parseError(key, val, "uint64", err)
+	}
+
+	return ret, true, err
+}
+
+// Uint64 returns the value from [EnvData.GetUint64], or the zero value when the variable is unset.
+func (p *EnvData) Uint64(key string) (uint64, error) {
+	val, _, err := p.GetUint64(key)
+
+	return val, err
+}
+
+// DefaultUint64 returns defaultVal when the variable is unset or [EnvData.GetUint64] fails to parse.
+func (p *EnvData) DefaultUint64(key string, defaultVal uint64) uint64 {
+	val, ok, err := p.GetUint64(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetBytes parses the value as a byte size such as 512MiB or 1.5GB. The second return value is false if the variable is unset.
+func (p *EnvData) GetBytes(key string) (uint64, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	ret, err := parseBytes(val)
+	if err != nil {
+		err = p.parseError(key, val, "byte size", err)
+	}
+
+	return ret, true, err
+}
+
+// Bytes returns the value from [EnvData.GetBytes], or the zero value when the variable is unset.
+func (p *EnvData) Bytes(key string) (uint64, error) {
+	val, _, err := p.GetBytes(key)
+
+	return val, err
+}
+
+// DefaultBytes returns defaultVal when the variable is unset or [EnvData.GetBytes] fails to parse.
+func (p *EnvData) DefaultBytes(key string, defaultVal uint64) uint64 {
+	val, ok, err := p.GetBytes(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetURL parses the value with [url.Parse]. The second return value is false if the variable is unset.
+func (p *EnvData) GetURL(key string) (*url.URL, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ret, err := url.Parse(val)
+	if err != nil {
+		err = p.parseError(key, val, "URL", err)
+	}
+
+	return ret, true, err
+}
+
+// URL returns the value from [EnvData.GetURL], or the zero value when the variable is unset.
+func (p *EnvData) URL(key string) (*url.URL, error) {
+	val, _, err := p.GetURL(key)
+
+	return val, err
+}
+
+// DefaultURL returns defaultVal when the variable is unset or [EnvData.GetURL] fails to parse.
+func (p *EnvData) DefaultURL(key string, defaultVal *url.URL) *url.URL {
+	val, ok, err := p.GetURL(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetAddr parses the value as an IP address with [netip.ParseAddr]. The second return value is false if the variable is unset.
+func (p *EnvData) GetAddr(key string) (netip.Addr, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return netip.Addr{}, false, nil
+	}
+
+	ret, err := netip.ParseAddr(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP address", err)
+	}
+
+	return ret, true, err
+}
+
+// Addr returns the value from [EnvData.GetAddr], or the zero value when the variable is unset.
+func (p *EnvData) Addr(key string) (netip.Addr, error) {
+	val, _, err := p.GetAddr(key)
+
+	return val, err
+}
+
+// DefaultAddr returns defaultVal when the variable is unset or [EnvData.GetAddr] fails to parse.
+func (p *EnvData) DefaultAddr(key string, defaultVal netip.Addr) netip.Addr {
+	val, ok, err := p.GetAddr(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetPrefix parses the value as an IP network prefix with [netip.ParsePrefix]. The second return value is false if the variable is unset.
+func (p *EnvData) GetPrefix(key string) (netip.Prefix, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return netip.Prefix{}, false, nil
+	}
+
+	ret, err := netip.ParsePrefix(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP prefix", err)
+	}
+
+	return ret, true, err
+}
+
+// Prefix returns the value from [EnvData.GetPrefix], or the zero value when the variable is unset.
+func (p *EnvData) Prefix(key string) (netip.Prefix, error) {
+	val, _, err := p.GetPrefix(key)
+
+	return val, err
+}
+
+// DefaultPrefix returns defaultVal when the variable is unset or [EnvData.GetPrefix] fails to parse.
+func (p *EnvData) DefaultPrefix(key string, defaultVal netip.Prefix) netip.Prefix {
+	val, ok, err := p.GetPrefix(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetAddrPort parses the value as an IP:port pair with [netip.ParseAddrPort]. The second return value is false if the variable is unset.
+func (p *EnvData) GetAddrPort(key string) (netip.AddrPort, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return netip.AddrPort{}, false, nil
+	}
+
+	ret, err := netip.ParseAddrPort(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP address and port", err)
+	}
+
+	return ret, true, err
+}
+
+// AddrPort returns the value from [EnvData.GetAddrPort], or the zero value when the variable is unset.
+func (p *EnvData) AddrPort(key string) (netip.AddrPort, error) {
+	val, _, err := p.GetAddrPort(key)
+
+	return val, err
+}
+
+// DefaultAddrPort returns defaultVal when the variable is unset or [EnvData.GetAddrPort] fails to parse.
+func (p *EnvData) DefaultAddrPort(key string, defaultVal netip.AddrPort) netip.AddrPort {
+	val, ok, err := p.GetAddrPort(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetHostPort validates the value as a host:port pair with a numeric port and returns it in canonical form. The second return value is false if the variable is unset.
+func (p *EnvData) GetHostPort(key string) (string, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return "", false, nil
+	}
+
+	ret, err := parseHostPort(val)
+	if err != nil {
+		err = p.parseError(key, val, "host:port", err)
+	}
+
+	return ret, true, err
+}
+
+// HostPort returns the value from [EnvData.GetHostPort], or the zero value when the variable is unset.
+func (p *EnvData) HostPort(key string) (string, error) {
+	val, _, err := p.GetHostPort(key)
+
+	return val, err
+}
+
+// DefaultHostPort returns defaultVal when the variable is unset or [EnvData.GetHostPort] fails to parse.
+func (p *EnvData) DefaultHostPort(key string, defaultVal string) string {
+	val, ok, err := p.GetHostPort(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetTime parses the value with [time.Parse] using layout. The second return value is false if the variable is unset.
+func (p *EnvData) GetTime(key string, layout string) (time.Time, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	ret, err := time.Parse(layout, val)
+	if err != nil {
+		err = p.parseError(key, val, "time", err)
+	}
+
+	return ret, true, err
+}
+
+// Time returns the value from [EnvData.GetTime], or the zero value when the variable is unset.
+func (p *EnvData) Time(key string, layout string) (time.Time, error) {
+	val, _, err := p.GetTime(key, layout)
+
+	return val, err
+}
+
+// DefaultTime returns defaultVal when the variable is unset or [EnvData.GetTime] fails to parse.
+func (p *EnvData) DefaultTime(key string, layout string, defaultVal time.Time) time.Time {
+	val, ok, err := p.GetTime(key, layout)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetLocation loads the time zone named by the value with [time.LoadLocation]. The second return value is false if the variable is unset.
+func (p *EnvData) GetLocation(key string) (*time.Location, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ret, err := time.LoadLocation(val)
+	if err != nil {
+		err = p.parseError(key, val, "time zone", err)
+	}
+
+	return ret, true, err
+}
+
+// Location returns the value from [EnvData.GetLocation], or the zero value when the variable is unset.
+func (p *EnvData) Location(key string) (*time.Location, error) {
+	val, _, err := p.GetLocation(key)
+
+	return val, err
+}
+
+// DefaultLocation returns defaultVal when the variable is unset or [EnvData.GetLocation] fails to parse.
+func (p *EnvData) DefaultLocation(key string, defaultVal *time.Location) *time.Location {
+	val, ok, err := p.GetLocation(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetRegexp compiles the value with [regexp.Compile]. The second return value is false if the variable is unset.
+func (p *EnvData) GetRegexp(key string) (*regexp.Regexp, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ret, err := regexp.Compile(val)
+	if err != nil {
+		err = p.parseError(key, val, "regular expression", err)
+	}
+
+	return ret, true, err
+}
+
+// Regexp returns the value from [EnvData.GetRegexp], or the zero value when the variable is unset.
+func (p *EnvData) Regexp(key string) (*regexp.Regexp, error) {
+	val, _, err := p.GetRegexp(key)
+
+	return val, err
+}
+
+// DefaultRegexp returns defaultVal when the variable is unset or [EnvData.GetRegexp] fails to parse.
+func (p *EnvData) DefaultRegexp(key string, defaultVal *regexp.Regexp) *regexp.Regexp {
+	val, ok, err := p.GetRegexp(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetFileMode parses the value as an octal permission such as 0644. The second return value is false if the variable is unset.
+func (p *EnvData) GetFileMode(key string) (os.FileMode, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	ret, err := parseFileMode(val)
+	if err != nil {
+		err = p.parseError(key, val, "file mode", err)
+	}
+
+	return ret, true, err
+}
+
+// FileMode returns the value from [EnvData.GetFileMode], or the zero value when the variable is unset.
+func (p *EnvData) FileMode(key string) (os.FileMode, error) {
+	val, _, err := p.GetFileMode(key)
+
+	return val, err
+}
+
+// DefaultFileMode returns defaultVal when the variable is unset or [EnvData.GetFileMode] fails to parse.
+func (p *EnvData) DefaultFileMode(key string, defaultVal os.FileMode) os.FileMode {
+	val, ok, err := p.GetFileMode(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetInts splits the value using sep and parses each element as a base-10 integer. The second return value is false if the variable is unset.
+func (p *EnvData) GetInts(key string, sep string) ([]int, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ret, err := parseInts(val, sep)
+	if err != nil {
+		err = p.parseError(key, val, "integer list", err)
+	}
+
+	return ret, true, err
+}
+
+// Ints returns the value from [EnvData.GetInts], or the zero value when the variable is unset.
+func (p *EnvData) Ints(key string, sep string) ([]int, error) {
+	val, _, err := p.GetInts(key, sep)
+
+	return val, err
+}
+
+// DefaultInts returns defaultVals when the variable is unset or [EnvData.GetInts] fails to parse.
+func (p *EnvData) DefaultInts(key string, sep string, defaultVals []int) []int {
+	val, ok, err := p.GetInts(key, sep)
+	if !ok || err != nil {
+		return defaultVals
+	}
+
+	return val
+}
+
+// Lookup implements [Source]. It returns the value from [EnvData.GetString] and never reports an error.
+func (p *EnvData) Lookup(key string) (string, bool, error) {
+	val, ok := p.GetString(key)
+
+	return val, ok, nil
+}
+
+// GetString returns the environment value for key after mapping SECTION::KEY with [EnvData.EnvName].
 func (p *EnvData) GetString(key string) (string, bool) {
 	return p.getData(key)
 }
@@ -208,13 +923,66 @@ func (p *EnvData) DefaultStrings(key string, sep string, defaultVals []string) [
 	return vals
 }
 
-// getData resolves key: for SECTION::KEY it looks up the name KEY_SECTION.
+// GetList splits the environment value with the rules of [IniData.GetList]: a JSON-style [...] array is decoded
+// element by element and any other value is split on [DefaultStringsSeparator]. The second return value is false if the variable is unset.
+func (p *EnvData) GetList(key string) ([]string, bool) {
+	val, ok := p.GetString(key)
+	if !ok {
+		return nil, false
+	}
+
+	return parseList(val), true
+}
+
+// List returns the slice from [EnvData.GetList], or nil when the variable is unset.
+func (p *EnvData) List(key string) []string {
+	vals, _ := p.GetList(key)
+
+	return vals
+}
+
+// DefaultList returns defaultVals when the variable is unset.
+func (p *EnvData) DefaultList(key string, defaultVals []string) []string {
+	vals, ok := p.GetList(key)
+	if !ok {
+		return defaultVals
+	}
+
+	return vals
+}
+
+// parseError returns err as a [*ParseError] for key and val with the source "env".
+func (p *EnvData) parseError(key string, val string, typ string, err error) error {
+	return &ParseError{
+		Key:    key,
+		Value:  val,
+		Type:   typ,
+		Source: "env",
+
+		Err: err,
+	}
+}
+
+// getData resolves key through [EnvData.EnvName]: by default SECTION::KEY is looked up as KEY_SECTION.
 func (p *EnvData) getData(key string) (string, bool) {
-	params := strings.Split(key, "::")
+	if !p.isServed(key) {
+		return "", false
+	}
+
+	return p.lookupEnv(p.EnvName(key))
+}
+
+// lookupEnv returns the variable name from the snapshot, or from the process environment in live mode.
+func (p *EnvData) lookupEnv(name string) (string, bool) {
+	p.RLock()
+	snapshot := p.snapshot
+	p.RUnlock()
 
-	if len(params) == 2 {
-		key = params[1] + "_" + params[0]
+	if snapshot == nil {
+		return os.LookupEnv(name)
 	}
 
-	return os.LookupEnv(key)
+	val, ok := snapshot[name]
+
+	return val, ok
 }
@@ -0,0 +1,196 @@
+package tcfg
+
+import (
+	"testing"
+)
+
+func TestEnvSnapshot(t *testing.T) {
+	t.Setenv("TCFG_SNAP_HOST", "a")
+	t.Setenv("TCFG_OTHER", "o")
+	t.Setenv("TCFG_EXTRA", "x")
+	t.Setenv("APP_NAME", "orders")
+	t.Setenv("PORT_DB", "1")
+	t.Setenv("TCFG_SNAP_PORT_DB", "2")
+
+	envData := NewEnvSnapshot("TCFG_SNAP_", "TCFG_EXTRA")
+
+	tests := []struct {
+		key string
+
+		want   string
+		wantOk bool
+	}{
+		{key: "TCFG_SNAP_HOST", want: "a", wantOk: true},
+		{key: "TCFG_OTHER"},
+		{key: "TCFG_EXTRA", want: "x", wantOk: true},
+		{key: "APP_NAME", want: "orders", wantOk: true},
+		{key: "DB::PORT"},
+		{key: "DB::TCFG_SNAP_PORT", want: "2", wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, ok := envData.GetString(tt.key)
+			if val != tt.want || ok != tt.wantOk {
+				t.Errorf("GetString(%q) = %q, %v, want %q, %v", tt.key, val, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	if !envData.IsSnapshot() {
+		t.Error("IsSnapshot() = false, want true")
+	}
+
+	t.Setenv("TCFG_SNAP_HOST", "b")
+
+	if val := envData.String("TCFG_SNAP_HOST"); val != "a" {
+		t.Errorf("String(TCFG_SNAP_HOST) before Refresh = %q, want %q", val, "a")
+	}
+
+	envData.Refresh()
+
+	if val := envData.String("TCFG_SNAP_HOST"); val != "b" {
+		t.Errorf("String(TCFG_SNAP_HOST) after Refresh = %q, want %q", val, "b")
+	}
+}
+
+func TestEnvDataFromList(t *testing.T) {
+	envData := NewEnvDataFromList([]string{"A=1", "B=x=y", "A=2", "INVALID", "EMPTY="})
+
+	tests := []struct {
+		key string
+
+		want   string
+		wantOk bool
+	}{
+		{key: "A", want: "2", wantOk: true},
+		{key: "B", want: "x=y", wantOk: true},
+		{key: "EMPTY", want: "", wantOk: true},
+		{key: "INVALID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, ok := envData.GetString(tt.key)
+			if val != tt.want || ok != tt.wantOk {
+				t.Errorf("GetString(%q) = %q, %v, want %q, %v", tt.key, val, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	t.Setenv("A", "live")
+	envData.Refresh()
+
+	if val := envData.String("A"); val != "2" {
+		t.Errorf("String(A) after Refresh = %q, want the list value %q", val, "2")
+	}
+}
+
+func TestEnvDataFromMap(t *testing.T) {
+	vals := map[string]string{"A": "1"}
+
+	envData := NewEnvDataFromMap(vals)
+
+	vals["A"] = "2"
+
+	if val := envData.String("A"); val != "1" {
+		t.Errorf("String(A) = %q, want the value copied at creation %q", val, "1")
+	}
+}
+
+func TestEnvDataLive(t *testing.T) {
+	envData := &EnvData{}
+
+	t.Setenv("TCFG_LIVE", "a")
+
+	if val := envData.String("TCFG_LIVE"); val != "a" {
+		t.Errorf("String(TCFG_LIVE) = %q, want %q", val, "a")
+	}
+
+	t.Setenv("TCFG_LIVE", "b")
+
+	if val := envData.String("TCFG_LIVE"); val != "b" {
+		t.Errorf("String(TCFG_LIVE) = %q, want %q", val, "b")
+	}
+
+	if envData.IsSnapshot() {
+		t.Error("IsSnapshot() = true, want false")
+	}
+}
+
+func TestEnvMapper(t *testing.T) {
+	custom := EnvMapperFunc(func(section string, key string) string {
+		return "APP_" + section + "_" + key
+	})
+
+	tests := []struct {
+		name   string
+		mapper EnvMapper
+		key    string
+
+		want string
+	}{
+		{name: "default", key: "DB::HOST", want: "HOST_DB"},
+		{name: "key section", mapper: EnvMapperKeySection, key: "DB::HOST", want: "HOST_DB"},
+		{name: "section key", mapper: EnvMapperSectionKey, key: "DB::HOST", want: "DB_HOST"},
+		{name: "section double key", mapper: EnvMapperSectionDoubleKey, key: "DB::MAX_CONN", want: "DB__MAX_CONN"},
+		{name: "dotted section", mapper: EnvMapperSectionKey, key: "DB.PRIMARY::HOST", want: "DB_PRIMARY_HOST"},
+		{name: "dotted section key first", key: "DB.PRIMARY::HOST", want: "HOST_DB_PRIMARY"},
+		{name: "custom", mapper: custom, key: "DB::HOST", want: "APP_DB_HOST"},
+		{name: "default section", mapper: EnvMapperSectionKey, key: "HOST", want: "HOST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envData := NewEnvDataFromMap(map[string]string{tt.want: "v"})
+
+			if tt.mapper != nil {
+				envData.SetMapper(tt.mapper)
+			}
+
+			if name := envData.EnvName(tt.key); name != tt.want {
+				t.Errorf("EnvName(%q) = %q, want %q", tt.key, name, tt.want)
+			}
+
+			if val, ok := envData.GetString(tt.key); !ok || val != "v" {
+				t.Errorf("GetString(%q) = %q, %v, want %q", tt.key, val, ok, "v")
+			}
+
+			section, _, ok := cutKey(tt.key)
+			if !ok {
+				return
+			}
+
+			if keys := envData.Keys("", section); len(keys) != 1 || keys[0] != tt.key {
+				t.Errorf("Keys(%q) = %q, want [%q]", section, keys, tt.key)
+			}
+		})
+	}
+
+	envData := NewEnvDataFromMap(nil)
+
+	envData.SetMapper(EnvMapperSectionKey)
+	envData.SetMapper(nil)
+
+	if name := envData.EnvName("DB::HOST"); name != "HOST_DB" {
+		t.Errorf("EnvName(DB::HOST) after SetMapper(nil) = %q, want %q", name, "HOST_DB")
+	}
+}
+
+func TestSetEnvMapper(t *testing.T) {
+	confData := newConfData(t, "[DB]\nHOST = ini\n[APP]\nDSN = ${DB::HOST}\n", map[string]string{"DB_HOST": "env"})
+
+	if val := confData.DefaultString("APP::DSN", ""); val != "ini" {
+		t.Errorf("String(APP::DSN) = %q, want %q", val, "ini")
+	}
+
+	confData.SetEnvMapper(EnvMapperSectionKey)
+
+	if val := confData.DefaultString("DB::HOST", ""); val != "env" {
+		t.Errorf("String(DB::HOST) = %q, want %q", val, "env")
+	}
+
+	if val := confData.DefaultString("APP::DSN", ""); val != "env" {
+		t.Errorf("String(APP::DSN) = %q, want %q", val, "env")
+	}
+}
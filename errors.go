@@ -0,0 +1,210 @@
+package tcfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrKeyNotFound matches every [*KeyNotFoundError] with [errors.Is].
+var ErrKeyNotFound = errors.New("tcfg: key not found")
+
+// ErrInterpolationCycle is wrapped by the [*InterpolationError] returned when a value refers back to a key that is
+// already being expanded, as in A=${B} and B=${A}.
+var ErrInterpolationCycle = errors.New("tcfg: interpolation cycle")
+
+// ErrInterpolationDepth is wrapped by the [*InterpolationError] returned when references nest deeper than the
+// maximum depth (see [ConfData.SetMaxDepth]).
+var ErrInterpolationDepth = errors.New("tcfg: maximum interpolation depth exceeded")
+
+// ErrExpansionLimit is wrapped by the [*InterpolationError] returned when $[] lists expand one value to more
+// alternatives than the maximum expansion (see [ConfData.SetMaxExpansion]).
+var ErrExpansionLimit = errors.New("tcfg: maximum expansion exceeded")
+
+// ErrIncludeCycle matches every [*IncludeCycleError] with [errors.Is].
+var ErrIncludeCycle = errors.New("tcfg: circular include chain")
+
+// ErrSectionCycle matches every [*SectionCycleError] with [errors.Is].
+var ErrSectionCycle = errors.New("tcfg: circular section inheritance")
+
+// ErrResponse matches every [*ResponseError] with [errors.Is].
+var ErrResponse = errors.New("tcfg: the config service returned an error")
+
+// ErrRemoteStatus matches every [*RemoteStatusError] with [errors.Is].
+var ErrRemoteStatus = errors.New("tcfg: unexpected status from the config service")
+
+// KeyNotFoundError reports that no layer holds a value for Key.
+type KeyNotFoundError struct {
+	Key string
+}
+
+// Error implements the error interface.
+func (e *KeyNotFoundError) Error() string {
+	return fmt.Sprintf("tcfg: the key %s was not found", e.Key)
+}
+
+// Is reports whether target is [ErrKeyNotFound].
+func (e *KeyNotFoundError) Is(target error) bool {
+	return target == ErrKeyNotFound
+}
+
+// ParseError reports a value that could not be converted to the requested type, or an INI line that could not be parsed.
+// Source is "env" for environment variables, the INI file path (or "config" for [IniMgr.ParseConfig]) otherwise.
+// Line is the 1-based INI line number, or zero when unknown. Err holds the underlying cause.
+type ParseError struct {
+	Key    string
+	Value  string
+	Type   string
+	Source string
+	Line   int
+
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "tcfg: cannot parse %q as %s", e.Value, e.Type)
+
+	if e.Key != "" {
+		fmt.Fprintf(&builder, " for key %s", e.Key)
+	}
+
+	if e.Source != "" {
+		if e.Line > 0 {
+			fmt.Fprintf(&builder, " (%s:%d)", e.Source, e.Line)
+		} else {
+			fmt.Fprintf(&builder, " (%s)", e.Source)
+		}
+	}
+
+	if e.Err != nil {
+		fmt.Fprintf(&builder, ": %v", e.Err)
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the underlying cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// InterpolationError reports a failure while expanding ${} or $[] placeholders in the value of Key.
+// Chain lists the keys being expanded, from Key to the reference that failed. Err holds the underlying cause,
+// such as a [*KeyNotFoundError] for a missing reference.
+type InterpolationError struct {
+	Key   string
+	Chain []string
+
+	Err error
+}
+
+// Error implements the error interface.
+func (e *InterpolationError) Error() string {
+	return fmt.Sprintf("tcfg: cannot interpolate the value of %s (%s): %v", e.Key, strings.Join(e.Chain, " -> "), e.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (e *InterpolationError) Unwrap() error {
+	return e.Err
+}
+
+// IncludeCycleError reports an include directive that leads back to a file already being parsed.
+// Chain lists the absolute file paths from the first file of the cycle back to itself.
+type IncludeCycleError struct {
+	Chain []string
+}
+
+// Error implements the error interface.
+func (e *IncludeCycleError) Error() string {
+	return fmt.Sprintf("tcfg: a circular include chain was detected: %s", strings.Join(e.Chain, " -> "))
+}
+
+// Is reports whether target is [ErrIncludeCycle].
+func (e *IncludeCycleError) Is(target error) bool {
+	return target == ErrIncludeCycle
+}
+
+// newChainError returns err as an [*InterpolationError] for the value of the last key in chain.
+func newChainError(chain []string, err error) error {
+	rootKey := ""
+	if len(chain) > 0 {
+		rootKey = chain[0]
+	}
+
+	return &InterpolationError{
+		Key:   rootKey,
+		Chain: chain,
+
+		Err: err,
+	}
+}
+
+// newInterpolationError returns an [*InterpolationError] for the first key of chain whose expansion failed at key.
+func newInterpolationError(chain []string, key string, err error) error {
+	rootKey := key
+	if len(chain) > 0 {
+		rootKey = chain[0]
+	}
+
+	return &InterpolationError{
+		Key:   rootKey,
+		Chain: append(append([]string{}, chain...), strings.ToUpper(strings.TrimSpace(key))),
+
+		Err: err,
+	}
+}
+
+// SectionCycleError reports INI sections that inherit from each other, as in [A : B] and [B : A].
+// Chain lists the sections from the first section of the cycle back to itself.
+type SectionCycleError struct {
+	Chain []string
+}
+
+// Error implements the error interface.
+func (e *SectionCycleError) Error() string {
+	return fmt.Sprintf("tcfg: a circular section inheritance was detected: %s", strings.Join(e.Chain, " -> "))
+}
+
+// Is reports whether target is [ErrSectionCycle].
+func (e *SectionCycleError) Is(target error) bool {
+	return target == ErrSectionCycle
+}
+
+// ResponseError reports a [Response] whose Error code is not zero. Code and Message are copied from the response.
+type ResponseError struct {
+	Code    int
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ResponseError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("tcfg: the config service returned error %d", e.Code)
+	}
+
+	return fmt.Sprintf("tcfg: the config service returned error %d: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is [ErrResponse].
+func (e *ResponseError) Is(target error) bool {
+	return target == ErrResponse
+}
+
+// RemoteStatusError reports an HTTP response from the config service that is neither 200 nor 304.
+type RemoteStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+// Error implements the error interface.
+func (e *RemoteStatusError) Error() string {
+	return fmt.Sprintf("tcfg: the config service at %s returned status %d", e.URL, e.StatusCode)
+}
+
+// Is reports whether target is [ErrRemoteStatus].
+func (e *RemoteStatusError) Is(target error) bool {
+	return target == ErrRemoteStatus
+}
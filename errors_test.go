@@ -0,0 +1,123 @@
+package tcfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorsIs(t *testing.T) {
+	cause := errors.New("cause")
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+
+		want bool
+	}{
+		{name: "key not found", err: &KeyNotFoundError{Key: "A"}, target: ErrKeyNotFound, want: true},
+		{
+			name:   "wrapped key not found",
+			err:    fmt.Errorf("x: %w", &KeyNotFoundError{Key: "A"}),
+			target: ErrKeyNotFound,
+			want:   true,
+		},
+		{name: "include cycle", err: &IncludeCycleError{Chain: []string{"a", "a"}}, target: ErrIncludeCycle, want: true},
+		{name: "include cycle is not key not found", err: &IncludeCycleError{}, target: ErrKeyNotFound},
+		{name: "parse error cause", err: &ParseError{Err: cause}, target: cause, want: true},
+		{
+			name:   "interpolation error cause",
+			err:    &InterpolationError{Key: "A", Err: &KeyNotFoundError{Key: "B"}},
+			target: ErrKeyNotFound,
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorSource(t *testing.T) {
+	confData := newConfData(t, "NAME = x\n\nPORT = 80a\n", map[string]string{"DEBUG": "maybe"})
+
+	tests := []struct {
+		name string
+		err  func() error
+
+		wantSource string
+		wantLine   int
+	}{
+		{
+			name: "ini",
+			err: func() error {
+				_, err := confData.Int("PORT")
+
+				return err
+			},
+			wantSource: "app.ini",
+			wantLine:   3,
+		},
+		{
+			name: "env",
+			err: func() error {
+				_, err := confData.Bool("DEBUG")
+
+				return err
+			},
+			wantSource: "env",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parseErr *ParseError
+			if err := tt.err(); !errors.As(err, &parseErr) {
+				t.Fatalf("error = %v, want a *ParseError", err)
+			}
+
+			if !strings.HasSuffix(parseErr.Source, tt.wantSource) || parseErr.Line != tt.wantLine {
+				t.Errorf("Source, Line = %q, %d, want %q, %d", parseErr.Source, parseErr.Line, tt.wantSource,
+					tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestIncludeCycleError(t *testing.T) {
+	dir := t.TempDir()
+
+	filePath := writeFile(t, dir, "a.ini", "include \"b.ini\"\n")
+	writeFile(t, dir, "b.ini", "include \"a.ini\"\n")
+
+	_, err := (&IniMgr{}).ParseFile(filePath)
+	if !errors.Is(err, ErrIncludeCycle) {
+		t.Fatalf("ParseFile() error = %v, want ErrIncludeCycle", err)
+	}
+
+	var cycleErr *IncludeCycleError
+	if !errors.As(err, &cycleErr) || len(cycleErr.Chain) != 3 || cycleErr.Chain[0] != cycleErr.Chain[2] {
+		t.Errorf("ParseFile() error = %v, want a chain from a.ini back to itself", err)
+	}
+}
+
+func TestInterpolationErrorMissingReference(t *testing.T) {
+	confData := newConfData(t, "URL = http://${HOST}\n", nil)
+
+	_, err := confData.String("URL")
+
+	var interpolationErr *InterpolationError
+	if !errors.As(err, &interpolationErr) || interpolationErr.Key != "URL" {
+		t.Fatalf("String(URL) error = %v, want an *InterpolationError for URL", err)
+	}
+
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("String(URL) error = %v, want it to wrap ErrKeyNotFound", err)
+	}
+}
@@ -0,0 +1,399 @@
+package tcfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrInvalidFormat is returned by [ConfData.Export] for a format it does not know.
+var ErrInvalidFormat = errors.New("tcfg: invalid export format")
+
+// ErrUnquotableValue is returned by [ConfData.Export] for a value that the parser cannot read back from INI or
+// JSON output in any form: one starting with '"', or one that must be quoted and ends with '"', since the parser
+// trims every quote around a quoted value.
+var ErrUnquotableValue = errors.New("tcfg: the value cannot be quoted")
+
+// ExportFormat names an output format of [ConfData.Export].
+type ExportFormat string
+
+// Formats supported by [ConfData.Export].
+const (
+	// FormatIni writes KEY = value lines under [SECTION] headers, readable by [IniMgr.ParseFile].
+	FormatIni ExportFormat = "ini"
+	// FormatJSON writes the keys and values as [Configs], readable by [IniMgr.ParseConfig].
+	FormatJSON ExportFormat = "json"
+	// FormatDotenv writes NAME=value lines named as the environment layer reads them. Dots in names, as in the
+	// map key LIMITS.READ, become '_', and keys whose names are still not identifiers are left out.
+	FormatDotenv ExportFormat = "dotenv"
+	// FormatShell writes export NAME=value lines for a POSIX shell, with names as for [FormatDotenv].
+	FormatShell ExportFormat = "shell"
+)
+
+var (
+	// RedactPatterns lists the [path.Match] patterns of the key names whose values [ConfData.Export] replaces with
+	// [RedactedValue] unless [ExportRedact] selects others. Patterns match the key without its section,
+	// case-insensitively.
+	RedactPatterns = []string{"*PASSWORD*", "*PASSWD*", "*SECRET*", "*TOKEN*", "*CREDENTIAL*", "*PRIVATE_KEY*", "*API_KEY*"}
+
+	// RedactedValue replaces the values of redacted keys.
+	RedactedValue = "******"
+)
+
+// ExportOption configures [ConfData.Export].
+type ExportOption func(*exportOptions)
+
+// exportOptions holds the settings of one [ConfData.Export] call.
+type exportOptions struct {
+	raw bool
+
+	redactPatterns []string
+}
+
+// ExportRaw writes values as stored, after the environment overlay but without expanding ${} and $[] placeholders.
+func ExportRaw() ExportOption {
+	return func(p *exportOptions) {
+		p.raw = true
+	}
+}
+
+// ExportRedact replaces [RedactPatterns] with patterns. Calling it without patterns disables redaction.
+func ExportRedact(patterns ...string) ExportOption {
+	return func(p *exportOptions) {
+		p.redactPatterns = append([]string{}, patterns...)
+	}
+}
+
+// exportEntry is one key written by [ConfData.Export].
+type exportEntry struct {
+	section string
+	key     string
+	val     string
+
+	// envName is the variable that holds the key in the environment layer.
+	envName string
+}
+
+// name returns the key of p in the SECTION::KEY form, or KEY in the default section.
+func (p *exportEntry) name() string {
+	if p.section == "" {
+		return p.key
+	}
+
+	return p.section + "::" + p.key
+}
+
+// Export writes the keys listed by [ConfData.Keys] and their values to w in format. Values are fully expanded,
+// as returned by [ConfData.String], unless [ExportRaw] is given, and the values of sensitive keys are redacted
+// (see [RedactPatterns]). INI and JSON output name keys as they are stored, with the key prefix of p and the
+// section of a view created by [ConfData.Sub], so that a ConfData configured like p reads the output back. A value
+// that fails to expand, or that INI or JSON output cannot hold (see [ErrUnquotableValue]), is returned as an error
+// before anything is written.
+func (p *ConfData) Export(w io.Writer, format ExportFormat, opts ...ExportOption) error {
+	if p == nil {
+		return ErrNilConfData
+	}
+
+	options := &exportOptions{
+		redactPatterns: RedactPatterns,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	entries, err := p.exportEntries(options)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatIni:
+		return exportIni(w, entries, options.raw)
+	case FormatJSON:
+		return exportJSON(w, entries, options.raw)
+	case FormatDotenv:
+		return exportLines(w, entries, "", quoteDotenv)
+	case FormatShell:
+		return exportLines(w, entries, "export ", quoteShell)
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidFormat, format)
+	}
+}
+
+// exportEntries resolves the keys of p for Export.
+func (p *ConfData) exportEntries(options *exportOptions) ([]*exportEntry, error) {
+	p.mutex.RLock()
+	envData := p.envData
+	p.mutex.RUnlock()
+
+	if envData == nil {
+		envData = &EnvData{}
+	}
+
+	keyPrefix := p.KeyPrefix()
+
+	keys := p.Keys()
+
+	entries := make([]*exportEntry, 0, len(keys))
+
+	for _, key := range keys {
+		var val string
+		var ok bool
+		var err error
+
+		if options.raw {
+			val, ok, err = p.stringEx(p.qualifyKey(key), sourceAll)
+		} else {
+			val, ok, err = p.GetString(key)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		section, tmpKey, _ := cutKey(p.qualifyKey(key))
+
+		entry := &exportEntry{
+			section: section,
+			key:     keyPrefix + tmpKey,
+			val:     val,
+		}
+
+		if isRedacted(tmpKey, options.redactPatterns) {
+			entry.val = RedactedValue
+		}
+
+		entry.envName = envData.EnvName(entry.name())
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// isRedacted reports whether key matches one of patterns.
+func isRedacted(key string, patterns []string) bool {
+	key = strings.ToUpper(key)
+
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(pattern), key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exportIni writes entries as INI text, default section first. Expanded values have their $ placeholders escaped
+// so that parsing the output yields the same values.
+func exportIni(w io.Writer, entries []*exportEntry, raw bool) error {
+	sections := make([]string, 0)
+	sectionEntries := make(map[string][]*exportEntry)
+
+	for _, entry := range entries {
+		if _, ok := sectionEntries[entry.section]; !ok {
+			if entry.section == "" {
+				sections = append([]string{""}, sections...)
+			} else {
+				sections = append(sections, entry.section)
+			}
+		}
+
+		sectionEntries[entry.section] = append(sectionEntries[entry.section], entry)
+	}
+
+	var builder strings.Builder
+
+	for index, section := range sections {
+		if section != "" {
+			if index > 0 {
+				builder.WriteByte('\n')
+			}
+
+			fmt.Fprintf(&builder, "[%s]\n", section)
+		}
+
+		for _, entry := range sectionEntries[section] {
+			val, ok := quoteIni(entry.val, raw)
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrUnquotableValue, entry.name())
+			}
+
+			fmt.Fprintf(&builder, "%s = %s\n", entry.key, val)
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// quoteIni returns val in the form the INI parser reads back as val. The bool is false when there is none.
+func quoteIni(val string, raw bool) (string, bool) {
+	if !raw {
+		val = escapePlaceholders(val)
+	}
+
+	val = strings.ReplaceAll(val, "\\n", "\\\\n")
+	val = strings.ReplaceAll(val, "\n", "\\n")
+
+	return quoteValue(val)
+}
+
+// quoteValue returns val, quoted when it has surrounding spaces, starts with '"' or has the shape of an inline array
+// or object, in the form the INI parser and [IniMgr.ParseConfig] read back as val. The bool is false when the
+// quotes would be trimmed together with a '"' at either end of val.
+func quoteValue(val string) (string, bool) {
+	isInline := (strings.HasPrefix(val, "[") && strings.HasSuffix(val, "]")) ||
+		(strings.HasPrefix(val, "{") && strings.HasSuffix(val, "}"))
+
+	if val == strings.TrimSpace(val) && !strings.HasPrefix(val, "\"") && !isInline {
+		return val, true
+	}
+
+	if strings.HasPrefix(val, "\"") || strings.HasSuffix(val, "\"") {
+		return "", false
+	}
+
+	return "\"" + val + "\"", true
+}
+
+// escapePlaceholders returns val with its ${} and $[] placeholders escaped as $${} and $$[], so that an expanded
+// value is read back literally.
+func escapePlaceholders(val string) string {
+	val = ValStringKeyMatchReg.ReplaceAllString(val, "$$${0}")
+
+	return ValStringsKeyMatchReg.ReplaceAllString(val, "$$${0}")
+}
+
+// exportJSON writes entries as [Configs]. Expanded values have their $ placeholders escaped and values are quoted
+// as [IniMgr.ParseConfig] expects, so that parsing the output yields the same values.
+func exportJSON(w io.Writer, entries []*exportEntry, raw bool) error {
+	configs := entriesToConfigs(entries)
+
+	err := quoteConfigs(configs, raw)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(configs)
+}
+
+// quoteConfigs quotes the values of configs in place for [IniMgr.ParseConfig], escaping their $ placeholders
+// unless raw is set. It returns [ErrUnquotableValue] for a value that cannot be quoted.
+func quoteConfigs(configs *Configs, raw bool) error {
+	for _, config := range configs.Configs {
+		val := config.Value
+		if !raw {
+			val = escapePlaceholders(val)
+		}
+
+		val, ok := quoteValue(val)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnquotableValue, config.Key)
+		}
+
+		config.Value = val
+	}
+
+	return nil
+}
+
+// entriesToConfigs returns entries as [Configs], with keys in the SECTION::KEY form.
+func entriesToConfigs(entries []*exportEntry) *Configs {
+	configs := &Configs{
+		Configs: make([]*Config, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		configs.Configs = append(configs.Configs, &Config{
+			Key:   entry.name(),
+			Value: entry.val,
+		})
+	}
+
+	return configs
+}
+
+// exportLines writes one NAME=value line per entry, named by the environment layer and preceded by prefix. Dots in
+// names become '_'; entries whose names are still not valid identifiers are skipped.
+func exportLines(w io.Writer, entries []*exportEntry, prefix string, quote func(string) string) error {
+	var builder strings.Builder
+
+	for _, entry := range entries {
+		name := strings.ReplaceAll(entry.envName, ".", "_")
+		if !isIdentifier(name) {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "%s%s=%s\n", prefix, name, quote(entry.val))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// isIdentifier reports whether name is a valid shell variable name: letters, digits and '_', not starting with a
+// digit.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isPlainValue reports whether val needs no quoting in dotenv and shell output.
+func isPlainValue(val string) bool {
+	for _, r := range val {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("_-.,:/@%+=", r):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// quoteDotenv returns val double-quoted with \, ", $ and newlines escaped, unless it needs no quoting.
+func quoteDotenv(val string) string {
+	if isPlainValue(val) {
+		return val
+	}
+
+	replacer := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "\n", "\\n", "\r", "\\r")
+
+	return "\"" + replacer.Replace(val) + "\""
+}
+
+// quoteShell returns val single-quoted for a POSIX shell, unless it needs no quoting.
+func quoteShell(val string) string {
+	if val != "" && isPlainValue(val) {
+		return val
+	}
+
+	return "'" + strings.ReplaceAll(val, "'", "'\\''") + "'"
+}
@@ -0,0 +1,271 @@
+package tcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestExportQuoting(t *testing.T) {
+	tests := []struct {
+		name string
+		val  string
+
+		wantIni    string
+		wantJSON   string
+		wantDotenv string
+		wantShell  string
+		wantErr    error
+	}{
+		{name: "plain", val: "abc", wantIni: "abc", wantJSON: "abc", wantDotenv: "abc", wantShell: "abc"},
+		{name: "empty", val: "", wantIni: "", wantJSON: "", wantDotenv: "", wantShell: "''"},
+		{
+			name:       "spaces",
+			val:        " a b ",
+			wantIni:    `" a b "`,
+			wantJSON:   `" a b "`,
+			wantDotenv: `" a b "`,
+			wantShell:  `' a b '`,
+		},
+		{
+			name:       "inline array",
+			val:        "[1,2]",
+			wantIni:    `"[1,2]"`,
+			wantJSON:   `"[1,2]"`,
+			wantDotenv: `"[1,2]"`,
+			wantShell:  `'[1,2]'`,
+		},
+		{name: "inline object", val: "{}", wantIni: `"{}"`, wantJSON: `"{}"`, wantDotenv: `"{}"`, wantShell: `'{}'`},
+		{name: "bracket", val: "[a", wantIni: "[a", wantJSON: "[a", wantDotenv: `"[a"`, wantShell: `'[a'`},
+		{
+			name:       "trailing quote",
+			val:        `say "hi"`,
+			wantIni:    `say "hi"`,
+			wantJSON:   `say "hi"`,
+			wantDotenv: `"say \"hi\""`,
+			wantShell:  `'say "hi"'`,
+		},
+		{
+			name:       "placeholder",
+			val:        "$${X}",
+			wantIni:    "$${X}",
+			wantJSON:   "$${X}",
+			wantDotenv: `"\${X}"`,
+			wantShell:  `'${X}'`,
+		},
+		{name: "newline", val: "a\nb", wantIni: `a\nb`, wantJSON: "a\nb", wantDotenv: `"a\nb"`, wantShell: "'a\nb'"},
+		{
+			name:       "escaped newline",
+			val:        `a\nb`,
+			wantIni:    `a\\nb`,
+			wantJSON:   `a\nb`,
+			wantDotenv: `"a\\nb"`,
+			wantShell:  `'a\nb'`,
+		},
+		{
+			name:       "single quote",
+			val:        "it's",
+			wantIni:    "it's",
+			wantJSON:   "it's",
+			wantDotenv: `"it's"`,
+			wantShell:  `'it'\''s'`,
+		},
+		{name: "leading quote", val: `"a`, wantErr: ErrUnquotableValue},
+		{name: "quoted", val: `"a"`, wantErr: ErrUnquotableValue},
+		{name: "spaces and trailing quote", val: ` a"`, wantErr: ErrUnquotableValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, "V = x\n", map[string]string{"V": tt.val})
+
+			var buf bytes.Buffer
+
+			err := confData.Export(&buf, FormatIni)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && buf.String() != "V = "+tt.wantIni+"\n") {
+				t.Errorf("Export(ini) = %q, %v, want %q, %v", buf.String(), err, "V = "+tt.wantIni+"\n", tt.wantErr)
+			}
+
+			buf.Reset()
+
+			err = confData.Export(&buf, FormatJSON)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Export(json) error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil {
+				var configs Configs
+
+				err = json.Unmarshal(buf.Bytes(), &configs)
+				if err != nil || len(configs.Configs) != 1 || configs.Configs[0].Value != tt.wantJSON {
+					t.Errorf("Export(json) = %s, want the value %q", buf.String(), tt.wantJSON)
+				}
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			buf.Reset()
+
+			err = confData.Export(&buf, FormatDotenv)
+			if err != nil || buf.String() != "V="+tt.wantDotenv+"\n" {
+				t.Errorf("Export(dotenv) = %q, %v, want %q", buf.String(), err, "V="+tt.wantDotenv+"\n")
+			}
+
+			buf.Reset()
+
+			err = confData.Export(&buf, FormatShell)
+			if err != nil || buf.String() != "export V="+tt.wantShell+"\n" {
+				t.Errorf("Export(shell) = %q, %v, want %q", buf.String(), err, "export V="+tt.wantShell+"\n")
+			}
+		})
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	src := `
+NAME = orders
+URL = http://${HOST}/$${PATH}
+HOST = " db.local "
+MYAPP_HOST = prefixed
+EMPTY =
+ARR = [ "x,y", "z" ]
+L[] = a,b
+L[] = c
+TEXT = line\nbreak
+LIMITS = { "read": 10 }
+[SERVER]
+PORT = 80
+BANNER = [v1]
+[SERVER.HTTP]
+MYAPP_PORT = 81
+`
+
+	tests := []struct {
+		name      string
+		keyPrefix string
+		sections  []string
+		format    ExportFormat
+		opts      []ExportOption
+	}{
+		{name: "ini", format: FormatIni},
+		{name: "ini raw", format: FormatIni, opts: []ExportOption{ExportRaw()}},
+		{name: "ini with a key prefix", keyPrefix: "MYAPP_", format: FormatIni},
+		{name: "ini view", sections: []string{"server"}, format: FormatIni},
+		{name: "json", format: FormatJSON},
+		{name: "json raw", format: FormatJSON, opts: []ExportOption{ExportRaw()}},
+		{name: "json with a key prefix", keyPrefix: "MYAPP_", format: FormatJSON},
+		{name: "json view", sections: []string{"server"}, format: FormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, nil, WithKeyPrefix(tt.keyPrefix))
+
+			for _, section := range tt.sections {
+				confData = confData.Sub(section)
+			}
+
+			var buf bytes.Buffer
+
+			err := confData.Export(&buf, tt.format, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var iniData *IniData
+
+			if tt.format == FormatIni {
+				iniData = parseIni(t, buf.String())
+			} else {
+				var configs Configs
+
+				err = json.Unmarshal(buf.Bytes(), &configs)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				iniData, err = (&IniMgr{}).ParseConfig(configs.Configs)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			readBack := newConfData(t, "", nil, WithIniData(iniData), WithKeyPrefix(tt.keyPrefix))
+
+			for _, section := range tt.sections {
+				readBack = readBack.Sub(section)
+			}
+
+			want := maps.Collect(confData.All())
+			got := maps.Collect(readBack.All())
+
+			if len(want) == 0 || !maps.Equal(got, want) {
+				t.Errorf("read back %v, want %v\n%s", got, want, buf.String())
+			}
+		})
+	}
+}
+
+func TestExportOptions(t *testing.T) {
+	src := "HOST = db\nURL = http://${HOST}\nDB_PASSWORD = secret\n[API]\nTOKEN = t\n"
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+
+		want string
+	}{
+		{
+			name: "default",
+			want: "DB_PASSWORD = ******\nHOST = db\nURL = http://db\n\n[API]\nTOKEN = ******\n",
+		},
+		{
+			name: "raw",
+			opts: []ExportOption{ExportRaw()},
+			want: "DB_PASSWORD = ******\nHOST = db\nURL = http://${HOST}\n\n[API]\nTOKEN = ******\n",
+		},
+		{
+			name: "redaction disabled",
+			opts: []ExportOption{ExportRedact()},
+			want: "DB_PASSWORD = secret\nHOST = db\nURL = http://db\n\n[API]\nTOKEN = t\n",
+		},
+		{
+			name: "redaction patterns",
+			opts: []ExportOption{ExportRedact("host", "U*")},
+			want: "DB_PASSWORD = secret\nHOST = ******\nURL = ******\n\n[API]\nTOKEN = t\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, nil)
+
+			var buf bytes.Buffer
+
+			err := confData.Export(&buf, FormatIni, tt.opts...)
+			if err != nil || buf.String() != tt.want {
+				t.Errorf("Export() = %q, %v, want %q", buf.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	confData := newConfData(t, "A = ${MISSING}\n", nil)
+
+	var buf bytes.Buffer
+
+	err := confData.Export(&buf, FormatIni)
+	if !errors.Is(err, ErrKeyNotFound) || buf.Len() != 0 {
+		t.Errorf("Export() = %q, %v, want nothing written and ErrKeyNotFound", buf.String(), err)
+	}
+
+	err = newConfData(t, "A = 1\n", nil).Export(&buf, "xml")
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Export(xml) error = %v, want ErrInvalidFormat", err)
+	}
+}
@@ -0,0 +1,325 @@
+package tcfg
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidFunc is returned by [RegisterFunc] for an empty or reserved name, a name that starts with a digit or
+// contains characters other than letters, digits and '_', or a nil function.
+var ErrInvalidFunc = errors.New("tcfg: invalid template function")
+
+// Func computes the value of a ${name:args} placeholder. args holds the comma-separated arguments after they have
+// been expanded; a comma inside an argument is written \,. ctx gives access to the configuration being expanded.
+type Func func(ctx *FuncContext, args []string) (string, error)
+
+// FuncContext lets a [Func] read other keys while a value is being expanded. Keys read through it take part in
+// cycle detection, so A = ${upper:A} fails instead of recursing.
+type FuncContext struct {
+	conf  *ConfData
+	chain []string
+}
+
+// String returns the expanded value of key, or a [*KeyNotFoundError] when key is missing.
+func (p *FuncContext) String(key string) (string, error) {
+	vals, ok, err := p.conf.resolve(key, p.chain)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", &KeyNotFoundError{Key: key}
+	}
+
+	return p.conf.joinValues(vals), nil
+}
+
+// List returns the expanded list elements of key, or a [*KeyNotFoundError] when key is missing.
+func (p *FuncContext) List(key string) ([]string, error) {
+	vals, ok, err := p.conf.resolveList(key, p.conf.getListSeparator(), p.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return vals, nil
+}
+
+var (
+	funcMutex sync.RWMutex
+
+	// funcs maps a function name to the Func called for ${name:args}. It is filled in init because the built-ins
+	// expand keys and so refer back to it.
+	funcs map[string]Func
+)
+
+func init() {
+	funcs = map[string]Func{
+		"upper":    funcUpper,
+		"lower":    funcLower,
+		"hostname": funcHostname,
+		"env_or":   funcEnvOr,
+		"base64":   funcBase64,
+		"base64d":  funcBase64d,
+		"join":     funcJoin,
+		"now":      funcNow,
+	}
+}
+
+// RegisterFunc registers fn as the template function called for ${name:args} and ${name}, replacing any previous
+// function of that name. Names are case-sensitive, hold letters, digits and '_', and do not start with a digit;
+// env, ini and self are reserved for reference namespaces. It is safe for concurrent use. Registered functions are
+// also available to templates (see [ConfData.SetTemplateMode]).
+//
+// A registered name followed by ':' always calls the function, so ${upper:APP_NAME} never reads a key named upper.
+// A bare ${name} reads the key name when it exists and calls the function otherwise.
+//
+// Built-in functions:
+//   - upper:KEY, lower:KEY change the case of the value of KEY
+//   - hostname returns [os.Hostname]
+//   - env_or:VAR,default returns the variable VAR of the environment layer, or default when it is unset
+//   - base64:KEY, base64d:KEY encode or decode the value of KEY with standard base64
+//   - join:KEY,sep joins the list elements of KEY with sep (default: the list separator)
+//   - now:layout formats the current time with layout (default: [time.RFC3339])
+func RegisterFunc(name string, fn Func) error {
+	if fn == nil || !isFuncName(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidFunc, name)
+	}
+
+	switch strings.ToLower(name) {
+	case NamespaceEnv, NamespaceIni, NamespaceSelf:
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidFunc, name)
+	}
+
+	funcMutex.Lock()
+
+	funcs[name] = fn
+
+	funcMutex.Unlock()
+
+	cacheGeneration.Add(1)
+
+	return nil
+}
+
+// getFunc returns the function registered under name.
+func getFunc(name string) (Func, bool) {
+	funcMutex.RLock()
+	defer funcMutex.RUnlock()
+
+	fn, ok := funcs[name]
+
+	return fn, ok
+}
+
+// isFuncName reports whether name is a valid function name.
+func isFuncName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	if name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+
+	for _, char := range name {
+		if char != '_' && (char < 'a' || char > 'z') && (char < 'A' || char > 'Z') && (char < '0' || char > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseFuncCall splits the body of a ${name:args} placeholder into a registered function and its raw arguments.
+// The bool is false when the text before the first ':' is not a registered function name.
+func parseFuncCall(expr string) (string, Func, string, bool) {
+	index := strings.Index(expr, ":")
+	if index <= 0 || strings.HasPrefix(expr[index:], "::") {
+		return "", nil, "", false
+	}
+
+	name := strings.TrimSpace(expr[:index])
+
+	fn, ok := getFunc(name)
+	if !ok {
+		return "", nil, "", false
+	}
+
+	return name, fn, expr[index+1:], true
+}
+
+// splitFuncArgs splits raw function arguments on commas outside nested ${...} and $[...] placeholders.
+// A \, outside placeholders is kept as a literal comma. An empty string yields no arguments.
+func splitFuncArgs(expr string) []string {
+	if expr == "" {
+		return nil
+	}
+
+	args := make([]string, 0)
+
+	var builder strings.Builder
+
+	depth := 0
+
+	for index := 0; index < len(expr); index++ {
+		char := expr[index]
+
+		switch {
+		case char == '$' && index+1 < len(expr) && (expr[index+1] == '{' || expr[index+1] == '['):
+			depth++
+
+			builder.WriteString(expr[index : index+2])
+			index++
+
+			continue
+		case depth > 0 && (char == '}' || char == ']'):
+			depth--
+		case depth == 0 && char == '\\' && index+1 < len(expr) && expr[index+1] == ',':
+			builder.WriteByte(',')
+			index++
+
+			continue
+		case depth == 0 && char == ',':
+			args = append(args, builder.String())
+			builder.Reset()
+
+			continue
+		}
+
+		builder.WriteByte(char)
+	}
+
+	return append(args, builder.String())
+}
+
+// callFunc expands the arguments of a function placeholder and calls fn with them.
+func (p *ConfData) callFunc(name string, fn Func, rawArgs string, chain []string) ([]string, error) {
+	rawVals := splitFuncArgs(rawArgs)
+
+	args := make([]string, 0, len(rawVals))
+
+	for _, rawVal := range rawVals {
+		vals, err := p.analysisValue(rawVal, chain)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, p.joinValues(vals))
+	}
+
+	ret, err := fn(&FuncContext{conf: p, chain: chain}, args)
+	if err != nil {
+		var interpolationErr *InterpolationError
+		if errors.As(err, &interpolationErr) {
+			return nil, err
+		}
+
+		return nil, newInterpolationError(chain, name, fmt.Errorf("tcfg: function %s failed: %w", name, err))
+	}
+
+	return []string{ret}, nil
+}
+
+// funcArg returns args[index], or defaultVal when there are fewer arguments.
+func funcArg(args []string, index int, defaultVal string) string {
+	if index < len(args) {
+		return args[index]
+	}
+
+	return defaultVal
+}
+
+// funcKeyValue returns the expanded value of the key named by the first argument.
+func funcKeyValue(ctx *FuncContext, args []string) (string, error) {
+	if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+		return "", errors.New("tcfg: a key argument is required")
+	}
+
+	return ctx.String(strings.TrimSpace(args[0]))
+}
+
+func funcUpper(ctx *FuncContext, args []string) (string, error) {
+	val, err := funcKeyValue(ctx, args)
+
+	return strings.ToUpper(val), err
+}
+
+func funcLower(ctx *FuncContext, args []string) (string, error) {
+	val, err := funcKeyValue(ctx, args)
+
+	return strings.ToLower(val), err
+}
+
+func funcHostname(ctx *FuncContext, args []string) (string, error) {
+	return os.Hostname()
+}
+
+func funcEnvOr(ctx *FuncContext, args []string) (string, error) {
+	name := strings.TrimSpace(funcArg(args, 0, ""))
+	if name == "" {
+		return "", errors.New("tcfg: a variable argument is required")
+	}
+
+	ctx.conf.mutex.RLock()
+	envData := ctx.conf.envData
+	ctx.conf.mutex.RUnlock()
+
+	if envData == nil {
+		envData = &EnvData{}
+	}
+
+	if val, ok := envData.getData(name); ok {
+		return val, nil
+	}
+
+	return funcArg(args, 1, ""), nil
+}
+
+func funcBase64(ctx *FuncContext, args []string) (string, error) {
+	val, err := funcKeyValue(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(val)), nil
+}
+
+func funcBase64d(ctx *FuncContext, args []string) (string, error) {
+	val, err := funcKeyValue(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(val))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func funcJoin(ctx *FuncContext, args []string) (string, error) {
+	if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+		return "", errors.New("tcfg: a key argument is required")
+	}
+
+	vals, err := ctx.List(strings.TrimSpace(args[0]))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(vals, funcArg(args, 1, ctx.conf.getListSeparator())), nil
+}
+
+func funcNow(ctx *FuncContext, args []string) (string, error) {
+	return time.Now().Format(funcArg(args, 0, time.RFC3339)), nil
+}
@@ -0,0 +1,93 @@
+package tcfg
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinFuncs(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := "NAME = Orders\nSECRET = c2VjcmV0\nHOSTS[] = a\nHOSTS[] = b\nupper = key\n"
+	env := map[string]string{"REGION": "eu"}
+
+	tests := []struct {
+		val string
+
+		want    string
+		wantErr error
+	}{
+		{val: "${upper:NAME}", want: "ORDERS"},
+		{val: "${lower:NAME}", want: "orders"},
+		{val: "${hostname}", want: hostname},
+		{val: "${env_or:REGION,us}", want: "eu"},
+		{val: "${env_or:ZONE,us}", want: "us"},
+		{val: "${base64:NAME}", want: "T3JkZXJz"},
+		{val: "${base64d:SECRET}", want: "secret"},
+		{val: "${join:HOSTS, }", want: "a b"},
+		{val: "${join:HOSTS}", want: "a,b"},
+		{val: `${join:HOSTS,\,}`, want: "a,b"},
+		{val: "${upper}", want: "key"},
+		{val: "${upper:MISSING}", wantErr: ErrKeyNotFound},
+		{val: "${upper:V}", wantErr: ErrInterpolationCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			confData := newConfData(t, src+"V = "+tt.val+"\n", env)
+
+			val, err := confData.String("V")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && val != tt.want) {
+				t.Errorf("String(V) = %q, %v, want %q, %v", val, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	err := RegisterFunc("test_repeat", func(ctx *FuncContext, args []string) (string, error) {
+		val, err := ctx.String(funcArg(args, 0, ""))
+
+		return strings.Repeat(val, len(args)), err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = RegisterFunc("test_fail", func(ctx *FuncContext, args []string) (string, error) {
+		return "", errFailed
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confData := newConfData(t, "A = ab\nV = ${test_repeat:A,x,y}\nW = ${test_fail}\n", nil)
+
+	if val, err := confData.String("V"); err != nil || val != "ababab" {
+		t.Errorf("String(V) = %q, %v, want %q", val, err, "ababab")
+	}
+
+	_, err = confData.String("W")
+
+	var interpolationErr *InterpolationError
+	if !errors.Is(err, errFailed) || !errors.As(err, &interpolationErr) {
+		t.Errorf("String(W) error = %v, want an *InterpolationError wrapping the function error", err)
+	}
+
+	for _, name := range []string{"", "1st", "a-b", "env", "INI", "self"} {
+		if err := RegisterFunc(name, funcUpper); !errors.Is(err, ErrInvalidFunc) {
+			t.Errorf("RegisterFunc(%q) error = %v, want ErrInvalidFunc", name, err)
+		}
+	}
+
+	if err := RegisterFunc("test_nil", nil); !errors.Is(err, ErrInvalidFunc) {
+		t.Errorf("RegisterFunc(nil) error = %v, want ErrInvalidFunc", err)
+	}
+}
@@ -0,0 +1,205 @@
+package tcfg
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNoParser is returned by [Get] when no parser is registered for the requested type and the type does not
+// implement [encoding.TextUnmarshaler].
+var ErrNoParser = errors.New("tcfg: no parser is registered for the requested type")
+
+// Source is a configuration source usable with [Get], [GetOr] and [MustGet]. [ConfData], [IniData] and [EnvData]
+// implement it. Lookup returns the value for key, whether the key exists, and an error when the value cannot be resolved.
+type Source interface {
+	Lookup(key string) (string, bool, error)
+}
+
+// listSource is implemented by sources that keep list values element by element, so that [Get] returns the
+// elements of key[] lines and [...] arrays intact.
+type listSource interface {
+	lookupList(key string) ([]string, bool, error)
+}
+
+// parseErrorSource is implemented by the package's own sources, which know where a value was read from.
+type parseErrorSource interface {
+	parseError(key string, val string, typ string, err error) error
+}
+
+var (
+	parserMutex sync.RWMutex
+
+	// parsers maps a reflect.Type to a func(string) (T, error) for that type.
+	parsers = map[reflect.Type]interface{}{
+		reflect.TypeFor[string](): func(val string) (string, error) {
+			return val, nil
+		},
+		reflect.TypeFor[bool](): func(val string) (bool, error) {
+			return parseBool(val)
+		},
+		reflect.TypeFor[int](): strconv.Atoi,
+		reflect.TypeFor[int32](): func(val string) (int32, error) {
+			ret, err := strconv.ParseInt(val, 10, 32)
+
+			return int32(ret), err
+		},
+		reflect.TypeFor[int64](): func(val string) (int64, error) {
+			return strconv.ParseInt(val, 10, 64)
+		},
+		reflect.TypeFor[uint](): func(val string) (uint, error) {
+			ret, err := strconv.ParseUint(val, 10, 0)
+
+			return uint(ret), err
+		},
+		reflect.TypeFor[uint64](): func(val string) (uint64, error) {
+			return strconv.ParseUint(val, 10, 64)
+		},
+		reflect.TypeFor[float32](): func(val string) (float32, error) {
+			ret, err := strconv.ParseFloat(val, 32)
+
+			return float32(ret), err
+		},
+		reflect.TypeFor[float64](): func(val string) (float64, error) {
+			return strconv.ParseFloat(val, 64)
+		},
+		reflect.TypeFor[time.Duration](): time.ParseDuration,
+		reflect.TypeFor[time.Time](): func(val string) (time.Time, error) {
+			return time.Parse(time.RFC3339, val)
+		},
+		reflect.TypeFor[*time.Location](): time.LoadLocation,
+		reflect.TypeFor[*url.URL]():       url.Parse,
+		reflect.TypeFor[netip.Addr]():     netip.ParseAddr,
+		reflect.TypeFor[netip.Prefix]():   netip.ParsePrefix,
+		reflect.TypeFor[netip.AddrPort](): netip.ParseAddrPort,
+		reflect.TypeFor[*regexp.Regexp](): regexp.Compile,
+		reflect.TypeFor[os.FileMode]():    parseFileMode,
+		reflect.TypeFor[[]string](): func(val string) ([]string, error) {
+			return parseList(val), nil
+		},
+		reflect.TypeFor[[]int](): func(val string) ([]int, error) {
+			return parseInts(val, DefaultStringsSeparator)
+		},
+	}
+)
+
+// RegisterParser registers parser as the conversion used by [Get], [GetOr] and [MustGet] for values of type T,
+// replacing any previous parser for T. It is safe for concurrent use.
+//
+// Built-in parsers cover string, bool, int, int32, int64, uint, uint64, float32, float64, [time.Duration],
+// [time.Time] (RFC 3339), *[time.Location], *[url.URL], [netip.Addr], [netip.Prefix], [netip.AddrPort],
+// *[regexp.Regexp], [os.FileMode], []string and []int.
+func RegisterParser[T any](parser func(string) (T, error)) {
+	parserMutex.Lock()
+
+	parsers[reflect.TypeFor[T]()] = parser
+
+	parserMutex.Unlock()
+}
+
+// getParser returns the parser registered for T. Types without a registered parser whose pointer implements
+// [encoding.TextUnmarshaler], such as log/slog.Level, are parsed with UnmarshalText.
+func getParser[T any]() (func(string) (T, error), bool) {
+	parserMutex.RLock()
+	parser, ok := parsers[reflect.TypeFor[T]()]
+	parserMutex.RUnlock()
+
+	if ok {
+		return parser.(func(string) (T, error)), true
+	}
+
+	var zero T
+
+	if _, ok := any(&zero).(encoding.TextUnmarshaler); ok {
+		return func(val string) (T, error) {
+			var ret T
+
+			err := any(&ret).(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+
+			return ret, err
+		}, true
+	}
+
+	return nil, false
+}
+
+// Get returns the value for key from source converted to T with the parser registered for T. A []string is read
+// from a [ConfData] or [IniData] like their GetList methods, so elements containing the separator stay intact.
+// A missing key yields a [*KeyNotFoundError]; an unsupported T yields an error wrapping [ErrNoParser].
+func Get[T any](source Source, key string) (T, error) {
+	var zero T
+
+	parser, ok := getParser[T]()
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrNoParser, reflect.TypeFor[T]())
+	}
+
+	if source, ok := source.(listSource); ok && reflect.TypeFor[T]() == reflect.TypeFor[[]string]() {
+		vals, ok, err := source.lookupList(key)
+		if err != nil {
+			return zero, err
+		}
+
+		if !ok {
+			return zero, &KeyNotFoundError{Key: key}
+		}
+
+		return any(vals).(T), nil
+	}
+
+	val, ok, err := source.Lookup(key)
+	if err != nil {
+		return zero, err
+	}
+
+	if !ok {
+		return zero, &KeyNotFoundError{Key: key}
+	}
+
+	ret, err := parser(val)
+	if err != nil {
+		typ := reflect.TypeFor[T]().String()
+
+		if source, ok := source.(parseErrorSource); ok {
+			return zero, source.parseError(key, val, typ, err)
+		}
+
+		return zero, &ParseError{
+			Key:   key,
+			Value: val,
+			Type:  typ,
+
+			Err: err,
+		}
+	}
+
+	return ret, nil
+}
+
+// GetOr returns defaultVal if [Get] would fail or the key is missing.
+func GetOr[T any](source Source, key string, defaultVal T) T {
+	ret, err := Get[T](source, key)
+	if err != nil {
+		return defaultVal
+	}
+
+	return ret
+}
+
+// MustGet returns the value from [Get] and panics if it fails.
+func MustGet[T any](source Source, key string) T {
+	ret, err := Get[T](source, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return ret
+}
@@ -0,0 +1,184 @@
+package tcfg
+
+import (
+	"errors"
+	"log/slog"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMode is a type without a built-in parser, registered in TestRegisterParser.
+type testMode int
+
+func TestGet(t *testing.T) {
+	confData := newConfData(t, `
+NAME = orders
+PORT = 8080
+DEBUG = on
+TIMEOUT = 1m30s
+ADDR = 10.0.0.1
+LEVEL = warn
+IDS = 1,2,3
+L[] = a,b
+L[] = c
+B[] = [1
+B[] = 2]
+`, nil)
+
+	tests := []struct {
+		name string
+		get  func() (any, error)
+
+		want any
+	}{
+		{name: "string", get: func() (any, error) { return Get[string](confData, "NAME") }, want: "orders"},
+		{name: "int", get: func() (any, error) { return Get[int](confData, "PORT") }, want: 8080},
+		{name: "uint64", get: func() (any, error) { return Get[uint64](confData, "PORT") }, want: uint64(8080)},
+		{name: "bool", get: func() (any, error) { return Get[bool](confData, "DEBUG") }, want: true},
+		{
+			name: "duration",
+			get:  func() (any, error) { return Get[time.Duration](confData, "TIMEOUT") },
+			want: 90 * time.Second,
+		},
+		{
+			name: "addr",
+			get:  func() (any, error) { return Get[netip.Addr](confData, "ADDR") },
+			want: netip.MustParseAddr("10.0.0.1"),
+		},
+		{name: "ints", get: func() (any, error) { return Get[[]int](confData, "IDS") }, want: []int{1, 2, 3}},
+		{
+			name: "native list",
+			get:  func() (any, error) { return Get[[]string](confData, "L") },
+			want: []string{"a,b", "c"},
+		},
+		{
+			name: "native list of brackets",
+			get:  func() (any, error) { return Get[[]string](confData, "B") },
+			want: []string{"[1", "2]"},
+		},
+		{
+			name: "text unmarshaler",
+			get:  func() (any, error) { return Get[slog.Level](confData, "LEVEL") },
+			want: slog.LevelWarn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSources(t *testing.T) {
+	iniData := parseIni(t, "L[] = a,b\nL[] = c\n")
+
+	vals, err := Get[[]string](iniData, "L")
+	if err != nil || !reflect.DeepEqual(vals, []string{"a,b", "c"}) {
+		t.Errorf("Get[[]string](IniData) = %q, %v, want %q", vals, err, []string{"a,b", "c"})
+	}
+
+	envData := NewEnvDataFromMap(map[string]string{"HOSTS": "a,b"})
+
+	vals, err = Get[[]string](envData, "HOSTS")
+	if err != nil || !reflect.DeepEqual(vals, []string{"a", "b"}) {
+		t.Errorf("Get[[]string](EnvData) = %q, %v, want %q", vals, err, []string{"a", "b"})
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	confData := newConfData(t, "PORT = 80a\n", nil)
+
+	_, err := Get[int](confData, "MISSING")
+
+	var keyNotFoundErr *KeyNotFoundError
+	if !errors.As(err, &keyNotFoundErr) || keyNotFoundErr.Key != "MISSING" {
+		t.Errorf("Get(MISSING) error = %v, want a *KeyNotFoundError", err)
+	}
+
+	_, err = Get[[]string](confData, "MISSING")
+	if !errors.As(err, &keyNotFoundErr) {
+		t.Errorf("Get[[]string](MISSING) error = %v, want a *KeyNotFoundError", err)
+	}
+
+	_, err = Get[int](confData, "PORT")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Type != "int" || parseErr.Value != "80a" || parseErr.Source == "" {
+		t.Errorf("Get(PORT) error = %#v, want a *ParseError citing its source", err)
+	}
+
+	_, err = Get[struct{}](confData, "PORT")
+	if !errors.Is(err, ErrNoParser) {
+		t.Errorf("Get[struct{}](PORT) error = %v, want ErrNoParser", err)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	confData := newConfData(t, "PORT = 8080\nBAD = x\n", nil)
+
+	tests := []struct {
+		key string
+
+		want int
+	}{
+		{key: "PORT", want: 8080},
+		{key: "BAD", want: 1},
+		{key: "MISSING", want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := GetOr(confData, tt.key, 1); got != tt.want {
+			t.Errorf("GetOr(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestMustGet(t *testing.T) {
+	confData := newConfData(t, "PORT = 8080\n", nil)
+
+	if got := MustGet[int](confData, "PORT"); got != 8080 {
+		t.Errorf("MustGet(PORT) = %d, want 8080", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGet(MISSING) did not panic")
+		}
+	}()
+
+	MustGet[int](confData, "MISSING")
+}
+
+func TestRegisterParser(t *testing.T) {
+	confData := newConfData(t, "MODE = fast\nBAD = other\n", nil)
+
+	RegisterParser(func(val string) (testMode, error) {
+		switch strings.ToLower(val) {
+		case "slow":
+			return 1, nil
+		case "fast":
+			return 2, nil
+		default:
+			return 0, errors.New("unknown mode")
+		}
+	})
+
+	mode, err := Get[testMode](confData, "MODE")
+	if err != nil || mode != 2 {
+		t.Errorf("Get[testMode](MODE) = %v, %v, want 2", mode, err)
+	}
+
+	_, err = Get[testMode](confData, "BAD")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Type != "tcfg.testMode" {
+		t.Errorf("Get[testMode](BAD) error = %v, want a *ParseError for tcfg.testMode", err)
+	}
+}
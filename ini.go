@@ -4,10 +4,15 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -39,6 +44,19 @@ var (
 	// SectionStartStr and SectionEndStr delimit INI section headers.
 	SectionStartStr = []byte{'['}
 	SectionEndStr   = []byte{']'}
+
+	// ArraySectionStartStr and ArraySectionEndStr delimit repeated section headers such as [[UPSTREAM]].
+	ArraySectionStartStr = []byte("[[")
+	ArraySectionEndStr   = []byte("]]")
+
+	// ListSuffixStr marks a key[] = value line that appends value to the list stored under key.
+	ListSuffixStr = "[]"
+	// MapKeySeparator joins a map key and its sub-key, as in key.sub = value.
+	MapKeySeparator = "."
+	// SectionSeparator joins nested section names, as in [server.http].
+	SectionSeparator = "."
+	// ProfileSeparator joins a section and a profile in profile sections such as [DB@prod].
+	ProfileSeparator = "@"
 )
 
 // IniMgr parses INI text from files or from in-memory [Config] rows.
@@ -86,10 +104,14 @@ func (p *IniMgr) ParseFile(filePath string) (*IniData, error) {
 		iniData := &IniData{
 			filePath: filePath,
 
-			data: make(map[string]map[string]string),
+			data:     make(map[string]map[string]string),
+			listData: make(map[string]map[string][]string),
 
 			secComment: make(map[string]string),
 			keyComment: make(map[string]string),
+			keyPos:     make(map[string]iniPos),
+
+			parents: make(map[string]string),
 
 			RWMutex: sync.RWMutex{},
 		}
@@ -97,18 +119,33 @@ func (p *IniMgr) ParseFile(filePath string) (*IniData, error) {
 		return iniData, nil
 	}
 
-	return p.parseFile(filePath, nil)
+	iniData, err := p.parseFile(filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = iniData.checkParents()
+	if err != nil {
+		return nil, err
+	}
+
+	return iniData, nil
 }
 
 // ParseConfig builds an [IniData] from configs. Keys are uppercased; values may be surrounded by quotes.
+// Keys ending in [] and unquoted [...] or {...} values are stored as lists and maps, as in [IniMgr.ParseFile].
 func (p *IniMgr) ParseConfig(configs []*Config) (*IniData, error) {
 	iniData := &IniData{
 		filePath: "config",
 
-		data: make(map[string]map[string]string),
+		data:     make(map[string]map[string]string),
+		listData: make(map[string]map[string][]string),
 
 		secComment: make(map[string]string),
 		keyComment: make(map[string]string),
+		keyPos:     make(map[string]iniPos),
+
+		parents: make(map[string]string),
 
 		RWMutex: sync.RWMutex{},
 	}
@@ -122,21 +159,21 @@ func (p *IniMgr) ParseConfig(configs []*Config) (*IniData, error) {
 		key := strings.ToUpper(config.Key)
 		val := strings.TrimSpace(config.Value)
 
-		params := strings.Split(key, "::")
-		if len(params) > 1 {
-			section = strings.TrimSpace(params[0])
-			key = strings.TrimSpace(params[1])
+		if tmpSection, tmpKey, ok := cutKey(key); ok {
+			section = strings.TrimSpace(tmpSection)
+			key = strings.TrimSpace(tmpKey)
 		}
 
 		if _, ok := iniData.data[section]; !ok {
 			iniData.data[section] = make(map[string]string)
 		}
 
-		if strings.HasPrefix(val, string(QuoteStr)) {
+		isQuoted := strings.HasPrefix(val, string(QuoteStr))
+		if isQuoted {
 			val = strings.Trim(val, string(QuoteStr))
 		}
 
-		iniData.data[section][key] = val
+		iniData.setValue(section, key, val, !isQuoted)
 	}
 
 	return iniData, nil
@@ -158,7 +195,7 @@ func (p *IniMgr) parseFile(filePath string, includeStack []string) (*IniData, er
 
 		cycle := append(append([]string{}, includeStack[index:]...), filePath)
 
-		return nil, fmt.Errorf("tcfg: a circular include chain was detected: %s", strings.Join(cycle, " -> "))
+		return nil, &IncludeCycleError{Chain: cycle}
 	}
 
 	data, err := os.ReadFile(filePath)
@@ -168,17 +205,30 @@ func (p *IniMgr) parseFile(filePath string, includeStack []string) (*IniData, er
 
 	nextStack := append(includeStack, filePath)
 
-	return p.parseData(filepath.Dir(filePath), data, nextStack)
+	iniData, err := p.parseData(filepath.Dir(filePath), data, nextStack)
+	if err != nil {
+		return nil, err
+	}
+
+	iniData.filePath = filePath
+
+	return iniData, nil
 }
 
 // parseData parses INI content from data. It strips a UTF-8 BOM, handles [section] headers, key=value lines,
-// include "path" directives, and comment blocks associated with sections or keys.
+// key[] = value list lines, include "path" directives, and comment blocks associated with sections or keys.
 func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*IniData, error) {
 	iniData := &IniData{
-		data: make(map[string]map[string]string),
+		data:     make(map[string]map[string]string),
+		listData: make(map[string]map[string][]string),
 
 		secComment: make(map[string]string),
 		keyComment: make(map[string]string),
+		keyPos:     make(map[string]iniPos),
+
+		parents: make(map[string]string),
+
+		repeated: make(map[string]bool),
 
 		RWMutex: sync.RWMutex{},
 	}
@@ -199,9 +249,18 @@ func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*Ini
 	var commentData bytes.Buffer
 	section := DefaultSection
 
+	filePath := ""
+	if len(includeStack) > 0 {
+		filePath = includeStack[len(includeStack)-1]
+	}
+
+	lineNum := 0
+
 	for {
 		isEof := false
 
+		lineNum++
+
 		line, err := buf.ReadBytes('\n')
 		if err == io.EOF {
 			isEof = true
@@ -244,7 +303,32 @@ func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*Ini
 		}
 
 		if bytes.HasPrefix(line, SectionStartStr) && bytes.HasSuffix(line, SectionEndStr) {
-			section = strings.ToUpper(string(line[1 : len(line)-1]))
+			var parent string
+
+			if bytes.HasPrefix(line, ArraySectionStartStr) && bytes.HasSuffix(line, ArraySectionEndStr) && len(line) > 4 {
+				section, parent = parseSectionHeader(string(line[2 : len(line)-2]))
+
+				section = section + SectionSeparator + strconv.Itoa(iniData.nextSectionIndex(section))
+
+				iniData.repeated[section] = true
+			} else {
+				section, parent = parseSectionHeader(string(line[1 : len(line)-1]))
+			}
+
+			if parent != "" {
+				if strings.Contains(section, ProfileSeparator) {
+					return nil, &ParseError{
+						Value:  string(line),
+						Type:   "section",
+						Source: filePath,
+						Line:   lineNum,
+
+						Err: errors.New("a profile section cannot declare a parent"),
+					}
+				}
+
+				iniData.parents[section] = parent
+			}
 
 			if commentData.Len() > 0 {
 				iniData.secComment[section] = commentData.String()
@@ -285,7 +369,19 @@ func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*Ini
 					return nil, err
 				}
 
-				for section, vals := range includeIniData.data {
+				renames := iniData.renumberRepeated(includeIniData)
+
+				rename := func(section string) string {
+					if tmpSection, ok := renames[section]; ok {
+						return tmpSection
+					}
+
+					return section
+				}
+
+				for tmpSection, vals := range includeIniData.data {
+					section := rename(tmpSection)
+
 					_, ok := iniData.data[section]
 					if !ok {
 						iniData.data[section] = make(map[string]string)
@@ -296,12 +392,37 @@ func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*Ini
 					}
 				}
 
+				for tmpSection, vals := range includeIniData.listData {
+					section := rename(tmpSection)
+
+					_, ok := iniData.listData[section]
+					if !ok {
+						iniData.listData[section] = make(map[string][]string)
+					}
+
+					for key, val := range vals {
+						iniData.listData[section][key] = val
+					}
+				}
+
 				for section, comment := range includeIniData.secComment {
-					iniData.secComment[section] = comment
+					iniData.secComment[rename(section)] = comment
 				}
 
 				for key, comment := range includeIniData.keyComment {
-					iniData.keyComment[key] = comment
+					iniData.keyComment[renameSectionKey(key, renames)] = comment
+				}
+
+				for key, pos := range includeIniData.keyPos {
+					iniData.keyPos[renameSectionKey(key, renames)] = pos
+				}
+
+				for section, parent := range includeIniData.parents {
+					iniData.parents[rename(section)] = rename(parent)
+				}
+
+				for section := range includeIniData.repeated {
+					iniData.repeated[rename(section)] = true
 				}
 
 				continue
@@ -309,12 +430,18 @@ func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*Ini
 		}
 
 		if len(params) != 2 {
-			return nil, fmt.Errorf("tcfg: invalid configuration line %q; expected KEY=VALUE syntax", string(line))
+			return nil, &ParseError{
+				Value:  string(line),
+				Type:   "KEY=VALUE",
+				Source: filePath,
+				Line:   lineNum,
+			}
 		}
 
 		val := bytes.TrimSpace(params[1])
 
-		if bytes.HasPrefix(val, QuoteStr) {
+		isQuoted := bytes.HasPrefix(val, QuoteStr)
+		if isQuoted {
 			val = bytes.Trim(val, string(QuoteStr))
 		}
 
@@ -323,7 +450,12 @@ func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*Ini
 		retVal = strings.ReplaceAll(retVal, "\\n", "\n")
 		retVal = strings.ReplaceAll(retVal, "$$n", "\\n")
 
-		iniData.data[section][key] = retVal
+		key = iniData.setValue(section, key, retVal, !isQuoted)
+
+		iniData.keyPos[section+"."+key] = iniPos{
+			filePath: filePath,
+			line:     lineNum,
+		}
 
 		if commentData.Len() > 0 {
 			iniData.keyComment[section+"."+key] = commentData.String()
@@ -345,14 +477,80 @@ func (p *IniMgr) parseData(dir string, data []byte, includeStack []string) (*Ini
 type IniData struct {
 	filePath string
 
-	data map[string]map[string]string // section=> key:val
+	data     map[string]map[string]string   // section=> key:val
+	listData map[string]map[string][]string // section=> key:vals, for key[] lines and [...] values
 
 	secComment map[string]string // section : comment
 	keyComment map[string]string // "section.KEY" : comment before the key line
+	keyPos     map[string]iniPos // "section.KEY" : file and line the key was read from
+
+	parents  map[string]string // section : parent section, from [CHILD : PARENT] headers
+	profiles []string          // active profiles, lowest priority first
+
+	repeated map[string]bool // sections numbered by [[SECTION]] headers, renumbered when included
 
 	sync.RWMutex
 }
 
+// iniPos records where an INI key was defined.
+type iniPos struct {
+	filePath string
+	line     int
+}
+
+// setValue stores val under section and key and returns the key without a list suffix. A key ending in [] appends
+// val to a list; when parseInline is true, [...] and {...} values are decoded as inline lists and maps.
+// List values are also stored joined by [DefaultStringsSeparator], with separators inside elements escaped as \,,
+// so that string accessors keep working and [splitList] restores the elements.
+// The caller must hold the write lock.
+func (p *IniData) setValue(section string, key string, val string, parseInline bool) string {
+	if _, ok := p.data[section]; !ok {
+		p.data[section] = make(map[string]string)
+	}
+
+	if _, ok := p.listData[section]; !ok {
+		p.listData[section] = make(map[string][]string)
+	}
+
+	if strings.HasSuffix(key, ListSuffixStr) {
+		key = strings.TrimSpace(strings.TrimSuffix(key, ListSuffixStr))
+
+		vals := append(p.listData[section][key], val)
+
+		p.listData[section][key] = vals
+		p.data[section][key] = joinList(vals, DefaultStringsSeparator)
+
+		return key
+	}
+
+	if parseInline {
+		if vals, ok := parseInlineList(val); ok {
+			p.listData[section][key] = vals
+			p.data[section][key] = joinList(vals, DefaultStringsSeparator)
+
+			return key
+		}
+
+		if vals, ok := parseInlineMap(val); ok {
+			delete(p.listData[section], key)
+
+			p.data[section][key] = val
+
+			for subKey, subVal := range vals {
+				p.setValue(section, key+MapKeySeparator+subKey, subVal, false)
+			}
+
+			return key
+		}
+	}
+
+	delete(p.listData[section], key)
+
+	p.data[section][key] = val
+
+	return key
+}
+
 // GetData returns a deep copy of all section maps. The caller may modify the returned maps without affecting p.
 func (p *IniData) GetData() map[string]map[string]string {
 	p.RLock()
@@ -377,6 +575,56 @@ func (p *IniData) GetData() map[string]map[string]string {
 	return iniData
 }
 
+// Sections returns the names of the parsed sections in sorted order, with [DefaultSection] first when present.
+// Profile sections such as DB@PROD are included under their own names.
+func (p *IniData) Sections() []string {
+	p.RLock()
+	defer p.RUnlock()
+
+	sections := make([]string, 0, len(p.data))
+
+	for section := range p.data {
+		if section != DefaultSection {
+			sections = append(sections, section)
+		}
+	}
+
+	sort.Strings(sections)
+
+	if _, ok := p.data[DefaultSection]; ok {
+		sections = append([]string{DefaultSection}, sections...)
+	}
+
+	return sections
+}
+
+// Keys returns the keys of p in sorted order, in the form accepted by the getters: KEY for the default section and
+// SECTION::KEY otherwise. Keys a section inherits from its parents or its active profiles are listed under the
+// section; profile sections are not listed on their own.
+func (p *IniData) Keys() []string {
+	keys := make([]string, 0)
+
+	for _, section := range p.Sections() {
+		if strings.Contains(section, ProfileSeparator) {
+			continue
+		}
+
+		sectionKeys, _ := p.sectionKeys(section)
+
+		for _, key := range sectionKeys {
+			if section == DefaultSection {
+				keys = append(keys, key)
+			} else {
+				keys = append(keys, section+"::"+key)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
 // GetBool returns the boolean value, whether the key exists, and a parse error if the stored string is not a valid boolean.
 func (p *IniData) GetBool(key string) (bool, bool, error) {
 	val, ok := p.getData(key)
@@ -385,6 +633,9 @@ func (p *IniData) GetBool(key string) (bool, bool, error) {
 	}
 
 	ret, err := parseBool(val)
+	if err != nil {
+		err = p.parseError(key, val, "bool", err)
+	}
 
 	return ret, true, err
 }
@@ -414,6 +665,9 @@ func (p *IniData) GetInt(key string) (int, bool, error) {
 	}
 
 	ret, err := strconv.Atoi(val)
+	if err != nil {
+		err = p.parseError(key, val, "int", err)
+	}
 
 	return ret, true, err
 }
@@ -443,6 +697,9 @@ func (p *IniData) GetInt64(key string) (int64, bool, error) {
 	}
 
 	ret, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		err = p.parseError(key, val, "int64", err)
+	}
 
 	return ret, true, err
 }
@@ -472,6 +729,9 @@ func (p *IniData) GetFloat(key string) (float64, bool, error) {
 	}
 
 	ret, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		err = p.parseError(key, val, "float64", err)
+	}
 
 	return ret, true, err
 }
@@ -501,6 +761,9 @@ func (p *IniData) GetDuration(key string) (time.Duration, bool, error) {
 	}
 
 	ret, err := time.ParseDuration(val)
+	if err != nil {
+		err = p.parseError(key, val, "duration", err)
+	}
 
 	return ret, true, err
 }
@@ -522,94 +785,1180 @@ func (p *IniData) DefaultDuration(key string, defaultVal time.Duration) time.Dur
 	return val
 }
 
-// GetString returns the raw string value for SECTION::KEY, or for the default section when no section is given.
-func (p *IniData) GetString(key string) (string, bool) {
-	return p.getData(key)
+// GetUint parses the value as a base-10 unsigned integer. The second return value is false when the key is missing.
+func (p *IniData) GetUint(key string) (uint, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	ret, err := strconv.ParseUint(val, 10, 0)
+	if err != nil {
+		err = p.parseError(key, val, "uint", err)
+	}
+
+	return uint(ret), true, err
 }
 
-// String returns the value from [IniData.GetString], or an empty string when the key is missing.
-func (p *IniData) String(key string) string {
-	val, _ := p.GetString(key)
+// Uint returns the value from [IniData.GetUint], or the zero value when the key is missing.
+func (p *IniData) Uint(key string) (uint, error) {
+	val, _, err := p.GetUint(key)
+
+	return val, err
+}
+
+// DefaultUint returns defaultVal when the key is missing or [IniData.GetUint] fails to parse.
+func (p *IniData) DefaultUint(key string, defaultVal uint) uint {
+	val, ok, err := p.GetUint(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
 
 	return val
 }
 
-// DefaultString returns defaultVal when the key is missing.
-func (p *IniData) DefaultString(key string, defaultVal string) string {
-	val, ok := p.GetString(key)
+// GetUint64 parses the value as a base-10 unsigned 64-bit integer. The second return value is false when the key is missing.
+func (p *IniData) GetUint64(key string) (uint64, bool, error) {
+	val, ok := p.getData(key)
 	if !ok {
+		return 0, false, nil
+	}
+
+	ret, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		err = p.parseError(key, val, "uint64", err)
+	}
+
+	return ret, true, err
+}
+
+// Uint64 returns the value from [IniData.GetUint64], or the zero value when the key is missing.
+func (p *IniData) Uint64(key string) (uint64, error) {
+	val, _, err := p.GetUint64(key)
+
+	return val, err
+}
+
+// DefaultUint64 returns defaultVal when the key is missing or [IniData.GetUint64] fails to parse.
+func (p *IniData) DefaultUint64(key string, defaultVal uint64) uint64 {
+	val, ok, err := p.GetUint64(key)
+	if !ok || err != nil {
 		return defaultVal
 	}
 
 	return val
 }
 
-// GetStrings splits the string value using sep. The second return value is false when the key is missing.
-func (p *IniData) GetStrings(key string, sep string) ([]string, bool) {
-	vals, ok := p.GetString(key)
+// GetBytes parses the value as a byte size such as 512MiB or 1.5GB. The second return value is false when the key is missing.
+func (p *IniData) GetBytes(key string) (uint64, bool, error) {
+	val, ok := p.getData(key)
 	if !ok {
-		return nil, false
+		return 0, false, nil
 	}
 
-	ret := strings.Split(vals, sep)
+	ret, err := parseBytes(val)
+	if err != nil {
+		err = p.parseError(key, val, "byte size", err)
+	}
 
-	return ret, true
+	return ret, true, err
 }
 
-// Strings returns the slice from [IniData.GetStrings], or nil when the key is missing.
-func (p *IniData) Strings(key string, sep string) []string {
-	vals, _ := p.GetStrings(key, sep)
+// Bytes returns the value from [IniData.GetBytes], or the zero value when the key is missing.
+func (p *IniData) Bytes(key string) (uint64, error) {
+	val, _, err := p.GetBytes(key)
 
-	return vals
+	return val, err
 }
 
-// DefaultStrings returns defaultVals when the key is missing.
-func (p *IniData) DefaultStrings(key string, sep string, defaultVals []string) []string {
-	vals, ok := p.GetStrings(key, sep)
+// DefaultBytes returns defaultVal when the key is missing or [IniData.GetBytes] fails to parse.
+func (p *IniData) DefaultBytes(key string, defaultVal uint64) uint64 {
+	val, ok, err := p.GetBytes(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetURL parses the value with [url.Parse]. The second return value is false when the key is missing.
+func (p *IniData) GetURL(key string) (*url.URL, bool, error) {
+	val, ok := p.getData(key)
 	if !ok {
-		return defaultVals
+		return nil, false, nil
 	}
 
-	return vals
+	ret, err := url.Parse(val)
+	if err != nil {
+		err = p.parseError(key, val, "URL", err)
+	}
+
+	return ret, true, err
 }
 
-// getData returns the value for an uppercased SECTION::KEY under the read lock.
-func (p *IniData) getData(key string) (string, bool) {
-	if key == "" {
-		return "", false
+// URL returns the value from [IniData.GetURL], or the zero value when the key is missing.
+func (p *IniData) URL(key string) (*url.URL, error) {
+	val, _, err := p.GetURL(key)
+
+	return val, err
+}
+
+// DefaultURL returns defaultVal when the key is missing or [IniData.GetURL] fails to parse.
+func (p *IniData) DefaultURL(key string, defaultVal *url.URL) *url.URL {
+	val, ok, err := p.GetURL(key)
+	if !ok || err != nil {
+		return defaultVal
 	}
 
-	p.RLock()
-	defer p.RUnlock()
+	return val
+}
+
+// GetAddr parses the value as an IP address with [netip.ParseAddr]. The second return value is false when the key is missing.
+func (p *IniData) GetAddr(key string) (netip.Addr, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return netip.Addr{}, false, nil
+	}
 
-	params := strings.Split(strings.ToUpper(key), "::")
+	ret, err := netip.ParseAddr(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP address", err)
+	}
+
+	return ret, true, err
+}
+
+// Addr returns the value from [IniData.GetAddr], or the zero value when the key is missing.
+func (p *IniData) Addr(key string) (netip.Addr, error) {
+	val, _, err := p.GetAddr(key)
 
-	tmpSection := DefaultSection
-	tmpKey := params[0]
+	return val, err
+}
 
-	if len(params) >= 2 {
-		tmpSection = params[0]
-		tmpKey = params[1]
+// DefaultAddr returns defaultVal when the key is missing or [IniData.GetAddr] fails to parse.
+func (p *IniData) DefaultAddr(key string, defaultVal netip.Addr) netip.Addr {
+	val, ok, err := p.GetAddr(key)
+	if !ok || err != nil {
+		return defaultVal
 	}
 
-	vals, ok := p.data[tmpSection]
+	return val
+}
+
+// GetPrefix parses the value as an IP network prefix with [netip.ParsePrefix]. The second return value is false when the key is missing.
+func (p *IniData) GetPrefix(key string) (netip.Prefix, bool, error) {
+	val, ok := p.getData(key)
 	if !ok {
-		return "", false
+		return netip.Prefix{}, false, nil
+	}
+
+	ret, err := netip.ParsePrefix(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP prefix", err)
 	}
 
-	val, ok := vals[tmpKey]
+	return ret, true, err
+}
+
+// Prefix returns the value from [IniData.GetPrefix], or the zero value when the key is missing.
+func (p *IniData) Prefix(key string) (netip.Prefix, error) {
+	val, _, err := p.GetPrefix(key)
 
-	return val, ok
+	return val, err
 }
 
-// toString returns a JSON representation of all section data for debugging.
-func (p *IniData) toString() (string, error) {
-	p.RLock()
-	defer p.RUnlock()
+// DefaultPrefix returns defaultVal when the key is missing or [IniData.GetPrefix] fails to parse.
+func (p *IniData) DefaultPrefix(key string, defaultVal netip.Prefix) netip.Prefix {
+	val, ok, err := p.GetPrefix(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
 
-	data, err := json.Marshal(p.data)
+	return val
+}
 
-	return string(data), err
+// GetAddrPort parses the value as an IP:port pair with [netip.ParseAddrPort]. The second return value is false when the key is missing.
+func (p *IniData) GetAddrPort(key string) (netip.AddrPort, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return netip.AddrPort{}, false, nil
+	}
+
+	ret, err := netip.ParseAddrPort(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP address and port", err)
+	}
+
+	return ret, true, err
+}
+
+// AddrPort returns the value from [IniData.GetAddrPort], or the zero value when the key is missing.
+func (p *IniData) AddrPort(key string) (netip.AddrPort, error) {
+	val, _, err := p.GetAddrPort(key)
+
+	return val, err
+}
+
+// DefaultAddrPort returns defaultVal when the key is missing or [IniData.GetAddrPort] fails to parse.
+func (p *IniData) DefaultAddrPort(key string, defaultVal netip.AddrPort) netip.AddrPort {
+	val, ok, err := p.GetAddrPort(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetHostPort validates the value as a host:port pair with a numeric port and returns it in canonical form. The second return value is false when the key is missing.
+func (p *IniData) GetHostPort(key string) (string, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return "", false, nil
+	}
+
+	ret, err := parseHostPort(val)
+	if err != nil {
+		err = p.parseError(key, val, "host:port", err)
+	}
+
+	return ret, true, err
+}
+
+// HostPort returns the value from [IniData.GetHostPort], or the zero value when the key is missing.
+func (p *IniData) HostPort(key string) (string, error) {
+	val, _, err := p.GetHostPort(key)
+
+	return val, err
+}
+
+// DefaultHostPort returns defaultVal when the key is missing or [IniData.GetHostPort] fails to parse.
+func (p *IniData) DefaultHostPort(key string, defaultVal string) string {
+	val, ok, err := p.GetHostPort(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetTime parses the value with [time.Parse] using layout. The second return value is false when the key is missing.
+func (p *IniData) GetTime(key string, layout string) (time.Time, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	ret, err := time.Parse(layout, val)
+	if err != nil {
+		err = p.parseError(key, val, "time", err)
+	}
+
+	return ret, true, err
+}
+
+// Time returns the value from [IniData.GetTime], or the zero value when the key is missing.
+func (p *IniData) Time(key string, layout string) (time.Time, error) {
+	val, _, err := p.GetTime(key, layout)
+
+	return val, err
+}
+
+// DefaultTime returns defaultVal when the key is missing or [IniData.GetTime] fails to parse.
+func (p *IniData) DefaultTime(key string, layout string, defaultVal time.Time) time.Time {
+	val, ok, err := p.GetTime(key, layout)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetLocation loads the time zone named by the value with [time.LoadLocation]. The second return value is false when the key is missing.
+func (p *IniData) GetLocation(key string) (*time.Location, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ret, err := time.LoadLocation(val)
+	if err != nil {
+		err = p.parseError(key, val, "time zone", err)
+	}
+
+	return ret, true, err
+}
+
+// Location returns the value from [IniData.GetLocation], or the zero value when the key is missing.
+func (p *IniData) Location(key string) (*time.Location, error) {
+	val, _, err := p.GetLocation(key)
+
+	return val, err
+}
+
+// DefaultLocation returns defaultVal when the key is missing or [IniData.GetLocation] fails to parse.
+func (p *IniData) DefaultLocation(key string, defaultVal *time.Location) *time.Location {
+	val, ok, err := p.GetLocation(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetRegexp compiles the value with [regexp.Compile]. The second return value is false when the key is missing.
+func (p *IniData) GetRegexp(key string) (*regexp.Regexp, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ret, err := regexp.Compile(val)
+	if err != nil {
+		err = p.parseError(key, val, "regular expression", err)
+	}
+
+	return ret, true, err
+}
+
+// Regexp returns the value from [IniData.GetRegexp], or the zero value when the key is missing.
+func (p *IniData) Regexp(key string) (*regexp.Regexp, error) {
+	val, _, err := p.GetRegexp(key)
+
+	return val, err
+}
+
+// DefaultRegexp returns defaultVal when the key is missing or [IniData.GetRegexp] fails to parse.
+func (p *IniData) DefaultRegexp(key string, defaultVal *regexp.Regexp) *regexp.Regexp {
+	val, ok, err := p.GetRegexp(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetFileMode parses the value as an octal permission such as 0644. The second return value is false when the key is missing.
+func (p *IniData) GetFileMode(key string) (os.FileMode, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	ret, err := parseFileMode(val)
+	if err != nil {
+		err = p.parseError(key, val, "file mode", err)
+	}
+
+	return ret, true, err
+}
+
+// FileMode returns the value from [IniData.GetFileMode], or the zero value when the key is missing.
+func (p *IniData) FileMode(key string) (os.FileMode, error) {
+	val, _, err := p.GetFileMode(key)
+
+	return val, err
+}
+
+// DefaultFileMode returns defaultVal when the key is missing or [IniData.GetFileMode] fails to parse.
+func (p *IniData) DefaultFileMode(key string, defaultVal os.FileMode) os.FileMode {
+	val, ok, err := p.GetFileMode(key)
+	if !ok || err != nil {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetInts splits the value using sep and parses each element as a base-10 integer. The second return value is false when the key is missing.
+func (p *IniData) GetInts(key string, sep string) ([]int, bool, error) {
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ret, err := parseInts(val, sep)
+	if err != nil {
+		err = p.parseError(key, val, "integer list", err)
+	}
+
+	return ret, true, err
+}
+
+// Ints returns the value from [IniData.GetInts], or the zero value when the key is missing.
+func (p *IniData) Ints(key string, sep string) ([]int, error) {
+	val, _, err := p.GetInts(key, sep)
+
+	return val, err
+}
+
+// DefaultInts returns defaultVals when the key is missing or [IniData.GetInts] fails to parse.
+func (p *IniData) DefaultInts(key string, sep string, defaultVals []int) []int {
+	val, ok, err := p.GetInts(key, sep)
+	if !ok || err != nil {
+		return defaultVals
+	}
+
+	return val
+}
+
+// Lookup implements [Source]. It returns the raw value from [IniData.GetString] and never reports an error.
+func (p *IniData) Lookup(key string) (string, bool, error) {
+	if p == nil {
+		return "", false, nil
+	}
+
+	val, ok := p.GetString(key)
+
+	return val, ok, nil
+}
+
+// lookupList implements listSource with [IniData.GetList].
+func (p *IniData) lookupList(key string) ([]string, bool, error) {
+	if p == nil {
+		return nil, false, nil
+	}
+
+	vals, ok := p.GetList(key)
+
+	return vals, ok, nil
+}
+
+// GetString returns the raw string value for SECTION::KEY, or for the default section when no section is given.
+func (p *IniData) GetString(key string) (string, bool) {
+	return p.getData(key)
+}
+
+// String returns the value from [IniData.GetString], or an empty string when the key is missing.
+func (p *IniData) String(key string) string {
+	val, _ := p.GetString(key)
+
+	return val
+}
+
+// DefaultString returns defaultVal when the key is missing.
+func (p *IniData) DefaultString(key string, defaultVal string) string {
+	val, ok := p.GetString(key)
+	if !ok {
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetStrings splits the string value using sep, keeping separators escaped with a backslash inside elements. The
+// second return value is false when the key is missing.
+func (p *IniData) GetStrings(key string, sep string) ([]string, bool) {
+	vals, ok := p.GetString(key)
+	if !ok {
+		return nil, false
+	}
+
+	ret := splitList(vals, sep)
+
+	return ret, true
+}
+
+// Strings returns the slice from [IniData.GetStrings], or nil when the key is missing.
+func (p *IniData) Strings(key string, sep string) []string {
+	vals, _ := p.GetStrings(key, sep)
+
+	return vals
+}
+
+// DefaultStrings returns defaultVals when the key is missing.
+func (p *IniData) DefaultStrings(key string, sep string, defaultVals []string) []string {
+	vals, ok := p.GetStrings(key, sep)
+	if !ok {
+		return defaultVals
+	}
+
+	return vals
+}
+
+// GetList returns the list stored for key. Values written as key[] lines or [...] arrays are returned element by
+// element; other values are split on [DefaultStringsSeparator]. The second return value is false when the key is missing.
+func (p *IniData) GetList(key string) ([]string, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	vals, ok := p.getList(key)
+	if ok {
+		return vals, true
+	}
+
+	val, ok := p.getData(key)
+	if !ok {
+		return nil, false
+	}
+
+	return parseList(val), true
+}
+
+// List returns the slice from [IniData.GetList], or nil when the key is missing.
+func (p *IniData) List(key string) []string {
+	vals, _ := p.GetList(key)
+
+	return vals
+}
+
+// DefaultList returns defaultVals when the key is missing.
+func (p *IniData) DefaultList(key string, defaultVals []string) []string {
+	vals, ok := p.GetList(key)
+	if !ok {
+		return defaultVals
+	}
+
+	return vals
+}
+
+// GetMap returns the entries written as key.sub = value lines or as a {...} object, keyed by the uppercased sub-key.
+// The second return value is false when no such entry exists.
+func (p *IniData) GetMap(key string) (map[string]string, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	tmpSection, tmpKey := splitKey(key)
+
+	p.RLock()
+	defer p.RUnlock()
+
+	prefix := tmpKey + MapKeySeparator
+
+	ret := make(map[string]string)
+
+	sections := p.sectionChain(tmpSection)
+
+	for index := len(sections) - 1; index >= 0; index-- {
+		for subKey, val := range p.data[sections[index]] {
+			if strings.HasPrefix(subKey, prefix) && len(subKey) > len(prefix) {
+				ret[strings.TrimPrefix(subKey, prefix)] = val
+			}
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil, false
+	}
+
+	return ret, true
+}
+
+// Map returns the map from [IniData.GetMap], or nil when the key is missing.
+func (p *IniData) Map(key string) map[string]string {
+	vals, _ := p.GetMap(key)
+
+	return vals
+}
+
+// DefaultMap returns defaultVals when the key is missing.
+func (p *IniData) DefaultMap(key string, defaultVals map[string]string) map[string]string {
+	vals, ok := p.GetMap(key)
+	if !ok {
+		return defaultVals
+	}
+
+	return vals
+}
+
+// getList returns a copy of the elements stored for key by key[] lines or a [...] array under the read lock.
+func (p *IniData) getList(key string) ([]string, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	tmpSection, tmpKey := splitKey(key)
+
+	p.RLock()
+	defer p.RUnlock()
+
+	section, ok := p.findSection(tmpSection, tmpKey)
+	if !ok {
+		return nil, false
+	}
+
+	vals, ok := p.listData[section][tmpKey]
+	if !ok {
+		return nil, false
+	}
+
+	return append([]string{}, vals...), true
+}
+
+// Sub returns a copy of the sections of p under section, renamed relative to it: section itself becomes the
+// default section and section.NAME becomes NAME. Profile overlays and parents within the subtree are kept, and
+// the active profiles are copied.
+func (p *IniData) Sub(section string) *IniData {
+	section = strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(section, "::", SectionSeparator)))
+
+	p.RLock()
+	defer p.RUnlock()
+
+	iniData := &IniData{
+		filePath: p.filePath,
+
+		data:     make(map[string]map[string]string),
+		listData: make(map[string]map[string][]string),
+
+		secComment: make(map[string]string),
+		keyComment: make(map[string]string),
+		keyPos:     make(map[string]iniPos),
+
+		parents:  make(map[string]string),
+		profiles: append([]string{}, p.profiles...),
+	}
+
+	// rename maps a section of p to its name in the copy.
+	rename := func(name string) (string, bool) {
+		base, profile, hasProfile := strings.Cut(name, ProfileSeparator)
+
+		switch {
+		case base == section:
+			base = DefaultSection
+		case strings.HasPrefix(base, section+SectionSeparator):
+			base = strings.TrimPrefix(base, section+SectionSeparator)
+		default:
+			return "", false
+		}
+
+		if hasProfile {
+			return base + ProfileSeparator + profile, true
+		}
+
+		return base, true
+	}
+
+	for name, vals := range p.data {
+		tmpName, ok := rename(name)
+		if !ok {
+			continue
+		}
+
+		iniData.data[tmpName] = make(map[string]string, len(vals))
+
+		for key, val := range vals {
+			iniData.data[tmpName][key] = val
+
+			if pos, ok := p.keyPos[name+"."+key]; ok {
+				iniData.keyPos[tmpName+"."+key] = pos
+			}
+
+			if comment, ok := p.keyComment[name+"."+key]; ok {
+				iniData.keyComment[tmpName+"."+key] = comment
+			}
+		}
+
+		if vals, ok := p.listData[name]; ok {
+			iniData.listData[tmpName] = make(map[string][]string, len(vals))
+
+			for key, val := range vals {
+				iniData.listData[tmpName][key] = append([]string{}, val...)
+			}
+		}
+
+		if comment, ok := p.secComment[name]; ok {
+			iniData.secComment[tmpName] = comment
+		}
+
+		if parent, ok := p.parents[name]; ok {
+			if tmpParent, ok := rename(parent); ok {
+				iniData.parents[tmpName] = tmpParent
+			}
+		}
+	}
+
+	return iniData
+}
+
+// sectionIndices returns the indices of the repeated sections of section, NAME.0, NAME.1 and so on, in ascending
+// order. Indices need not be contiguous. The caller holds the read lock.
+func (p *IniData) sectionIndices(section string) []int {
+	indices := make([]int, 0)
+
+	for name := range p.data {
+		tmpIndex, ok := strings.CutPrefix(name, section+SectionSeparator)
+		if !ok {
+			continue
+		}
+
+		index, err := strconv.Atoi(tmpIndex)
+		if err != nil || index < 0 || strconv.Itoa(index) != tmpIndex {
+			continue
+		}
+
+		indices = append(indices, index)
+	}
+
+	sort.Ints(indices)
+
+	return indices
+}
+
+// nextSectionIndex returns the index the next [[section]] header is stored under: one past the highest existing
+// index, or zero. The caller holds the lock.
+func (p *IniData) nextSectionIndex(section string) int {
+	indices := p.sectionIndices(section)
+	if len(indices) == 0 {
+		return 0
+	}
+
+	return indices[len(indices)-1] + 1
+}
+
+// renumberRepeated returns the new names of the sections of includeIniData for merging into p: the sections of
+// its [[SECTION]] headers are numbered after the highest index p already holds, in their original order, so that
+// they are appended rather than merged into the blocks of p. Other sections keep their names. The caller holds
+// the lock of p.
+func (p *IniData) renumberRepeated(includeIniData *IniData) map[string]string {
+	renames := make(map[string]string)
+
+	bases := make(map[string][]int)
+
+	for section := range includeIniData.repeated {
+		index := strings.LastIndex(section, SectionSeparator)
+
+		tmpIndex, err := strconv.Atoi(section[index+1:])
+		if index < 0 || err != nil {
+			continue
+		}
+
+		bases[section[:index]] = append(bases[section[:index]], tmpIndex)
+	}
+
+	for base, indices := range bases {
+		sort.Ints(indices)
+
+		next := p.nextSectionIndex(base)
+
+		for _, index := range indices {
+			renames[base+SectionSeparator+strconv.Itoa(index)] = base + SectionSeparator + strconv.Itoa(next)
+
+			next++
+		}
+	}
+
+	return renames
+}
+
+// renameSectionKey applies renames to the section of a "section.KEY" metadata key.
+func renameSectionKey(key string, renames map[string]string) string {
+	for section, tmpSection := range renames {
+		if tmpKey, ok := strings.CutPrefix(key, section+"."); ok {
+			return tmpSection + "." + tmpKey
+		}
+	}
+
+	return key
+}
+
+// parseSectionHeader splits the text between [ and ] into the uppercased section name and the parent named after
+// ':', as in [DB_PROD : DB]. The parent is empty when none is given.
+func parseSectionHeader(header string) (string, string) {
+	section, parent, _ := strings.Cut(strings.ToUpper(header), ":")
+
+	return strings.TrimSpace(section), strings.TrimSpace(parent)
+}
+
+// SetProfiles sets the active profiles, lowest priority first. A lookup in section DB then reads DB@PROFILE for
+// each active profile, the last one first, before DB itself. Profiles are matched case-insensitively. Cached values
+// of every [ConfData] are discarded.
+func (p *IniData) SetProfiles(profiles ...string) {
+	p.setProfiles(profiles...)
+
+	// Every ConfData reading p may have cached values of the previous profiles.
+	cacheGeneration.Add(1)
+}
+
+// setProfiles sets the active profiles without discarding any cache.
+func (p *IniData) setProfiles(profiles ...string) {
+	tmpProfiles := make([]string, 0, len(profiles))
+
+	for _, profile := range profiles {
+		profile = strings.ToUpper(strings.TrimSpace(profile))
+		if profile != "" {
+			tmpProfiles = append(tmpProfiles, profile)
+		}
+	}
+
+	p.Lock()
+
+	p.profiles = tmpProfiles
+
+	p.Unlock()
+}
+
+// Profiles returns the active profiles, lowest priority first.
+func (p *IniData) Profiles() []string {
+	p.RLock()
+	defer p.RUnlock()
+
+	return append([]string{}, p.profiles...)
+}
+
+// sectionChain returns the sections consulted for a lookup in section, highest priority first: the profile
+// overlays of section, section itself, then the same for each parent in turn. The caller holds the read lock.
+func (p *IniData) sectionChain(section string) []string {
+	sections := make([]string, 0, len(p.profiles)+1)
+
+	visited := make(map[string]bool)
+
+	for section != "" && !visited[section] {
+		visited[section] = true
+
+		for index := len(p.profiles) - 1; index >= 0; index-- {
+			sections = append(sections, section+ProfileSeparator+p.profiles[index])
+		}
+
+		sections = append(sections, section)
+
+		section = p.parents[section]
+	}
+
+	return sections
+}
+
+// findSection returns the first section of [IniData.sectionChain] that defines key. The caller holds the read lock.
+func (p *IniData) findSection(section string, key string) (string, bool) {
+	if len(p.parents) == 0 && len(p.profiles) == 0 {
+		_, ok := p.data[section][key]
+
+		return section, ok
+	}
+
+	for _, tmpSection := range p.sectionChain(section) {
+		if _, ok := p.data[tmpSection][key]; ok {
+			return tmpSection, true
+		}
+	}
+
+	return section, false
+}
+
+// sectionKeys returns the keys visible in section, including keys inherited from parents and profile overlays.
+// The bool is false when neither section nor any of its overlays exists.
+func (p *IniData) sectionKeys(section string) ([]string, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	found := false
+
+	keysMap := make(map[string]bool)
+
+	for _, tmpSection := range p.sectionChain(section) {
+		vals, ok := p.data[tmpSection]
+		if !ok {
+			continue
+		}
+
+		found = true
+
+		for key := range vals {
+			keysMap[key] = true
+		}
+	}
+
+	if !found {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(keysMap))
+
+	for key := range keysMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys, true
+}
+
+// checkParents reports a parent section that does not exist as a [*ParseError] and an inheritance loop as a
+// [*SectionCycleError].
+func (p *IniData) checkParents() error {
+	p.RLock()
+	defer p.RUnlock()
+
+	sections := make([]string, 0, len(p.parents))
+
+	for section := range p.parents {
+		sections = append(sections, section)
+	}
+
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		parent := p.parents[section]
+
+		if _, ok := p.data[parent]; !ok {
+			return &ParseError{
+				Key:    section,
+				Value:  parent,
+				Type:   "section",
+				Source: p.filePath,
+
+				Err: fmt.Errorf("the parent section %s does not exist", parent),
+			}
+		}
+
+		chain := []string{section}
+
+		for tmpSection := parent; tmpSection != ""; tmpSection = p.parents[tmpSection] {
+			chain = append(chain, tmpSection)
+
+			if tmpSection == section {
+				return &SectionCycleError{Chain: chain}
+			}
+
+			if len(chain) > len(p.parents)+1 {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// splitKey returns the uppercased section and key named by SECTION::KEY, using [DefaultSection] when no section is given.
+// Nested sections may be written A.B::KEY or A::B::KEY.
+func splitKey(key string) (string, string) {
+	section, key, ok := cutKey(strings.ToUpper(key))
+	if !ok {
+		return DefaultSection, key
+	}
+
+	return section, key
+}
+
+// cutKey splits SECTION::KEY at its last "::". Nested sections written A::B::KEY are joined with '.', giving
+// section A.B as in a [A.B] header. The bool is false when key names no section.
+func cutKey(key string) (string, string, bool) {
+	index := strings.LastIndex(key, "::")
+	if index < 0 {
+		return "", key, false
+	}
+
+	return strings.ReplaceAll(key[:index], "::", SectionSeparator), key[index+2:], true
+}
+
+// parseError returns err as a [*ParseError] for key and val, citing the file and line the key was read from.
+func (p *IniData) parseError(key string, val string, typ string, err error) error {
+	filePath, line := p.position(key)
+
+	return &ParseError{
+		Key:    key,
+		Value:  val,
+		Type:   typ,
+		Source: filePath,
+		Line:   line,
+
+		Err: err,
+	}
+}
+
+// position returns the file and line key was read from. Keys without a recorded line, such as inline map entries
+// and [IniMgr.ParseConfig] rows, report the file of p and line zero.
+func (p *IniData) position(key string) (string, int) {
+	tmpSection, tmpKey := splitKey(key)
+
+	p.RLock()
+	defer p.RUnlock()
+
+	section, _ := p.findSection(tmpSection, tmpKey)
+
+	pos, ok := p.keyPos[section+"."+tmpKey]
+	if !ok {
+		return p.filePath, 0
+	}
+
+	return pos.filePath, pos.line
+}
+
+// getData returns the value for an uppercased SECTION::KEY under the read lock.
+func (p *IniData) getData(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+
+	tmpSection, tmpKey := splitKey(key)
+
+	section, ok := p.findSection(tmpSection, tmpKey)
+	if !ok {
+		return "", false
+	}
+
+	return p.data[section][tmpKey], true
+}
+
+// toString returns a JSON representation of all section data for debugging.
+func (p *IniData) toString() (string, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	data, err := json.Marshal(p.data)
+
+	return string(data), err
+}
+
+// parseList splits val into list elements. A JSON-style [...] array is decoded element by element;
+// any other value is split on [DefaultStringsSeparator] (see [splitList] for escaping), and an empty value yields an
+// empty slice.
+func parseList(val string) []string {
+	if vals, ok := parseInlineList(val); ok {
+		return vals
+	}
+
+	if val == "" {
+		return []string{}
+	}
+
+	return splitList(val, DefaultStringsSeparator)
+}
+
+// splitInlineList is like parseList but splits values that are not inline arrays on sep.
+func splitInlineList(val string, sep string) []string {
+	if vals, ok := parseInlineList(val); ok {
+		return vals
+	}
+
+	if val == "" {
+		return []string{}
+	}
+
+	return splitList(val, sep)
+}
+
+// splitList splits val on sep. A separator preceded by a backslash, as in a\,b, is kept in the element with the
+// backslash removed.
+func splitList(val string, sep string) []string {
+	if sep == "" || !strings.Contains(val, "\\"+sep) {
+		return strings.Split(val, sep)
+	}
+
+	vals := make([]string, 0, strings.Count(val, sep)+1)
+
+	var builder strings.Builder
+
+	for index := 0; index < len(val); {
+		if strings.HasPrefix(val[index:], "\\"+sep) {
+			builder.WriteString(sep)
+			index += 1 + len(sep)
+
+			continue
+		}
+
+		if strings.HasPrefix(val[index:], sep) {
+			vals = append(vals, builder.String())
+			builder.Reset()
+			index += len(sep)
+
+			continue
+		}
+
+		builder.WriteByte(val[index])
+		index++
+	}
+
+	return append(vals, builder.String())
+}
+
+// joinList joins vals with sep, escaping separators inside elements so that [splitList] restores vals.
+func joinList(vals []string, sep string) string {
+	escVals := make([]string, 0, len(vals))
+
+	for _, val := range vals {
+		escVals = append(escVals, strings.ReplaceAll(val, sep, "\\"+sep))
+	}
+
+	return strings.Join(escVals, sep)
+}
+
+// parseInlineList decodes a JSON-style array such as [ "a", "b,c" ]. Scalar elements are converted to strings and
+// nested values are kept as JSON text. The bool is false when val is not a valid array.
+func parseInlineList(val string) ([]string, bool) {
+	val = strings.TrimSpace(val)
+	if !strings.HasPrefix(val, "[") || !strings.HasSuffix(val, "]") {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(val))
+	decoder.UseNumber()
+
+	var items []interface{}
+
+	err := decoder.Decode(&items)
+	if err != nil {
+		return nil, false
+	}
+
+	vals := make([]string, 0, len(items))
+
+	for _, item := range items {
+		vals = append(vals, formatInlineValue(item))
+	}
+
+	return vals, true
+}
+
+// parseInlineMap decodes a JSON-style object such as { "host": "db", "port": 5432 } into uppercased sub-keys.
+// Nested objects are flattened with [MapKeySeparator]. The bool is false when val is not a valid object.
+func parseInlineMap(val string) (map[string]string, bool) {
+	val = strings.TrimSpace(val)
+	if !strings.HasPrefix(val, "{") || !strings.HasSuffix(val, "}") {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(val))
+	decoder.UseNumber()
+
+	var items map[string]interface{}
+
+	err := decoder.Decode(&items)
+	if err != nil {
+		return nil, false
+	}
+
+	vals := make(map[string]string, len(items))
+
+	for key, item := range items {
+		key = strings.ToUpper(strings.TrimSpace(key))
+
+		if subItems, ok := item.(map[string]interface{}); ok {
+			data, _ := json.Marshal(subItems)
+
+			subVals, _ := parseInlineMap(string(data))
+			for subKey, subVal := range subVals {
+				vals[key+MapKeySeparator+subKey] = subVal
+			}
+
+			continue
+		}
+
+		vals[key] = formatInlineValue(item)
+	}
+
+	return vals, true
+}
+
+// formatInlineValue converts a decoded inline list or map element to its string form.
+func formatInlineValue(item interface{}) string {
+	switch v := item.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	data, _ := json.Marshal(item)
+
+	return string(data)
 }
 
 // parseBool interprets val as a boolean. Supported forms include common string literals and numeric 0/1 for integer and float64 types.
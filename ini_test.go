@@ -0,0 +1,352 @@
+package tcfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile writes src to the file name in dir and returns its path.
+func writeFile(t *testing.T, dir string, name string, src string) string {
+	t.Helper()
+
+	filePath := filepath.Join(dir, name)
+
+	err := os.WriteFile(filePath, []byte(src), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+// parseIni parses src as the contents of an INI file.
+func parseIni(t *testing.T, src string) *IniData {
+	t.Helper()
+
+	iniData, err := (&IniMgr{}).ParseFile(writeFile(t, t.TempDir(), "app.ini", src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return iniData
+}
+
+func TestIniDataList(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		key  string
+
+		want    []string
+		wantStr string
+	}{
+		{
+			name:    "list lines",
+			src:     "L[] = a\nL[] = b\n",
+			key:     "L",
+			want:    []string{"a", "b"},
+			wantStr: "a,b",
+		},
+		{
+			name:    "list lines with separators",
+			src:     "L[] = a,b\nL[] = c\n",
+			key:     "L",
+			want:    []string{"a,b", "c"},
+			wantStr: `a\,b,c`,
+		},
+		{
+			name:    "inline array",
+			src:     `L = [ "x,y", "z" ]`,
+			key:     "L",
+			want:    []string{"x,y", "z"},
+			wantStr: `x\,y,z`,
+		},
+		{
+			name:    "inline array of scalars",
+			src:     `L = [ 1, true, "s" ]`,
+			key:     "L",
+			want:    []string{"1", "true", "s"},
+			wantStr: "1,true,s",
+		},
+		{
+			name:    "list lines in a section",
+			src:     "[DB]\nHOSTS[] = a\nHOSTS[] = b\n",
+			key:     "DB::HOSTS",
+			want:    []string{"a", "b"},
+			wantStr: "a,b",
+		},
+		{
+			name:    "plain value",
+			src:     "L = a,b",
+			key:     "L",
+			want:    []string{"a", "b"},
+			wantStr: "a,b",
+		},
+		{
+			name:    "plain value replaces a list",
+			src:     "L[] = a\nL = b",
+			key:     "L",
+			want:    []string{"b"},
+			wantStr: "b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iniData := parseIni(t, tt.src)
+
+			vals, ok := iniData.GetList(tt.key)
+			if !ok || !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("GetList(%q) = %q, %v, want %q", tt.key, vals, ok, tt.want)
+			}
+
+			val, ok := iniData.GetString(tt.key)
+			if !ok || val != tt.wantStr {
+				t.Errorf("GetString(%q) = %q, %v, want %q", tt.key, val, ok, tt.wantStr)
+			}
+
+			vals, ok = iniData.GetStrings(tt.key, DefaultStringsSeparator)
+			if !ok || !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("GetStrings(%q) = %q, %v, want %q", tt.key, vals, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestIniDataMap(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		key  string
+
+		want map[string]string
+	}{
+		{
+			name: "sub-key lines",
+			src:  "DB.HOST = localhost\nDB.PORT = 5432\n",
+			key:  "DB",
+			want: map[string]string{"HOST": "localhost", "PORT": "5432"},
+		},
+		{
+			name: "inline object",
+			src:  `LIMITS = { "read": 10, "write": 5.5, "on": true }`,
+			key:  "LIMITS",
+			want: map[string]string{"READ": "10", "WRITE": "5.5", "ON": "true"},
+		},
+		{
+			name: "nested inline object",
+			src:  `LIMITS = { "api": { "read": 10 }, "tags": ["a", "b"] }`,
+			key:  "LIMITS",
+			want: map[string]string{"API.READ": "10", "TAGS": `["a","b"]`},
+		},
+		{
+			name: "inline object in a section",
+			src:  "[APP]\nLIMITS = { \"read\": 10 }\n",
+			key:  "APP::LIMITS",
+			want: map[string]string{"READ": "10"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iniData := parseIni(t, tt.src)
+
+			vals, ok := iniData.GetMap(tt.key)
+			if !ok || !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("GetMap(%q) = %v, %v, want %v", tt.key, vals, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestIniDataMapQuoted(t *testing.T) {
+	iniData := parseIni(t, `LIMITS = "{ \"read\": 10 }"`)
+
+	if _, ok := iniData.GetMap("LIMITS"); ok {
+		t.Error("GetMap(LIMITS) found a map in a quoted value")
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		val string
+		sep string
+
+		want []string
+	}{
+		{val: "a,b", sep: ",", want: []string{"a", "b"}},
+		{val: `a\,b,c`, sep: ",", want: []string{"a,b", "c"}},
+		{val: `a\;b;c`, sep: ";", want: []string{"a;b", "c"}},
+		{val: `a\,b;c`, sep: ";", want: []string{`a\,b`, "c"}},
+		{val: "a::b", sep: "::", want: []string{"a", "b"}},
+		{val: "", sep: ",", want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			vals := splitList(tt.val, tt.sep)
+			if !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("splitList(%q, %q) = %q, want %q", tt.val, tt.sep, vals, tt.want)
+			}
+
+			if val := joinList(vals, tt.sep); !reflect.DeepEqual(splitList(val, tt.sep), vals) {
+				t.Errorf("splitList(joinList(%q)) = %q, want %q", vals, splitList(val, tt.sep), vals)
+			}
+		})
+	}
+}
+
+func TestIniDataInheritance(t *testing.T) {
+	src := `
+[BASE]
+HOST = base
+PORT = 5432
+USER = admin
+
+[DB : BASE]
+HOST = db
+
+[DB@STAGING]
+HOST = staging
+USER = stage
+
+[DB@PROD]
+HOST = prod
+
+[BASE@PROD]
+PORT = 6432
+
+[REPLICA : DB]
+`
+
+	tests := []struct {
+		name     string
+		profiles []string
+		key      string
+
+		want   string
+		wantOk bool
+	}{
+		{name: "own key", key: "DB::HOST", want: "db", wantOk: true},
+		{name: "inherited key", key: "DB::PORT", want: "5432", wantOk: true},
+		{name: "grandparent key", key: "REPLICA::USER", want: "admin", wantOk: true},
+		{name: "missing key", key: "DB::NAME"},
+		{name: "profile", profiles: []string{"prod"}, key: "DB::HOST", want: "prod", wantOk: true},
+		{name: "last profile wins", profiles: []string{"prod", "staging"}, key: "DB::HOST", want: "staging", wantOk: true},
+		{name: "reversed", profiles: []string{"staging", "prod"}, key: "DB::HOST", want: "prod", wantOk: true},
+		{name: "earlier profile", profiles: []string{"staging", "prod"}, key: "DB::USER", want: "stage", wantOk: true},
+		{name: "profile of a parent", profiles: []string{"prod"}, key: "DB::PORT", want: "6432", wantOk: true},
+		{name: "profile of an ancestor", profiles: []string{"prod"}, key: "REPLICA::HOST", want: "prod", wantOk: true},
+		{name: "unknown profile", profiles: []string{"dev"}, key: "DB::HOST", want: "db", wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iniData := parseIni(t, src)
+
+			iniData.SetProfiles(tt.profiles...)
+
+			val, ok := iniData.GetString(tt.key)
+			if val != tt.want || ok != tt.wantOk {
+				t.Errorf("GetString(%q) = %q, %v, want %q, %v", tt.key, val, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestIniDataParentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+
+		wantChain []string
+		wantValue string
+	}{
+		{name: "self", src: "[A : A]\n", wantChain: []string{"A", "A"}},
+		{name: "two sections", src: "[A : B]\n[B : A]\n", wantChain: []string{"A", "B", "A"}},
+		{name: "three sections", src: "[A : C]\n[B : A]\n[C : B]\n", wantChain: []string{"A", "C", "B", "A"}},
+		{name: "missing parent", src: "[A : MISSING]\n", wantValue: "MISSING"},
+		{name: "profile with a parent", src: "[A]\n[B@PROD : A]\n", wantValue: "[B@PROD : A]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&IniMgr{}).ParseFile(writeFile(t, t.TempDir(), "app.ini", tt.src))
+
+			if tt.wantChain != nil {
+				var cycleErr *SectionCycleError
+				if !errors.Is(err, ErrSectionCycle) || !errors.As(err, &cycleErr) ||
+					!reflect.DeepEqual(cycleErr.Chain, tt.wantChain) {
+					t.Errorf("ParseFile() error = %v, want a cycle %q", err, tt.wantChain)
+				}
+
+				return
+			}
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || parseErr.Value != tt.wantValue {
+				t.Errorf("ParseFile() error = %#v, want a *ParseError for %s", err, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestIniDataRepeatedSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		include string
+
+		want map[string]string
+	}{
+		{
+			name: "blocks",
+			src:  "[[up]]\nHOST = a\n[[UP]]\nHOST = b\n",
+			want: map[string]string{"UP.0": "a", "UP.1": "b"},
+		},
+		{
+			name: "explicit index",
+			src:  "[UP.3]\nHOST = a\n[[UP]]\nHOST = b\n[UP.1]\nHOST = c\n",
+			want: map[string]string{"UP.1": "c", "UP.3": "a", "UP.4": "b"},
+		},
+		{
+			name: "nested",
+			src:  "[[UP.TLS]]\nHOST = a\n[[UP.TLS]]\nHOST = b\n",
+			want: map[string]string{"UP.TLS.0": "a", "UP.TLS.1": "b"},
+		},
+		{
+			name:    "include appends blocks",
+			src:     "[[UP]]\nHOST = a\ninclude \"inc.ini\"\n[[UP]]\nHOST = d\n",
+			include: "[[UP]]\nHOST = b\n[UP.3]\nHOST = c\n",
+			want:    map[string]string{"UP.0": "a", "UP.1": "b", "UP.3": "c", "UP.4": "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			if tt.include != "" {
+				writeFile(t, dir, "inc.ini", tt.include)
+			}
+
+			iniData, err := (&IniMgr{}).ParseFile(writeFile(t, dir, "app.ini", tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			vals := make(map[string]string)
+
+			for _, section := range iniData.Sections() {
+				vals[section] = iniData.DefaultString(section+"::HOST", "")
+			}
+
+			if !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("sections = %v, want %v", vals, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package tcfg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrValueRequired is wrapped by the [*InterpolationError] returned for a ${KEY:?message} placeholder whose key is
+// missing or empty. The error text includes the expanded message.
+var ErrValueRequired = errors.New("tcfg: a required value is missing")
+
+// ErrInvalidPlaceholder is wrapped by the [*InterpolationError] returned for a ${...} placeholder that cannot be parsed.
+var ErrInvalidPlaceholder = errors.New("tcfg: invalid placeholder")
+
+// Namespaces that may prefix a key in ${...} and $[...] placeholders, as in ${env:HOME}.
+const (
+	// NamespaceEnv reads the key from the environment only.
+	NamespaceEnv = "env"
+	// NamespaceIni reads the key from INI data only.
+	NamespaceIni = "ini"
+	// NamespaceSelf reads the key from the section of the value being expanded.
+	NamespaceSelf = "self"
+)
+
+// sourceMask selects the layers consulted when resolving a key.
+type sourceMask int
+
+const (
+	sourceEnv sourceMask = 1 << iota
+	sourceIni
+
+	sourceAll = sourceEnv | sourceIni
+)
+
+// Placeholder operators supported inside ${...}, following the shell parameter expansion syntax.
+const (
+	opDefault   = ":-" // ${KEY:-word}: word when KEY is missing or empty
+	opRequired  = ":?" // ${KEY:?word}: error with message word when KEY is missing or empty
+	opAlternate = ":+" // ${KEY:+word}: word when KEY is set and not empty, otherwise empty
+	opSubstring = ":"  // ${KEY:offset} and ${KEY:offset:length}
+	opUpperAll  = "^^" // ${KEY^^}: uppercase
+	opUpper     = "^"  // ${KEY^}: uppercase the first character
+	opLowerAll  = ",," // ${KEY,,}: lowercase
+	opLower     = ","  // ${KEY,}: lowercase the first character
+)
+
+// placeholderEnd returns the index just past the } closing the ${ at start, counting nested braces so that
+// ${A:-${B}} is matched as a whole. It returns fallback when the braces are unbalanced.
+func placeholderEnd(val string, start int, fallback int) int {
+	depth := 0
+
+	for index := start + 1; index < len(val); index++ {
+		switch val[index] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return index + 1
+			}
+		}
+	}
+
+	return fallback
+}
+
+// parsePlaceholder splits the body of a ${...} placeholder into the key name, the operator and the operator's word.
+// The key name, including any env:, ini: or self: namespace, ends at the first ':' that is not part of a SECTION::KEY
+// separator, or at a '^' or ','.
+func parsePlaceholder(expr string) (string, string, string) {
+	index := 0
+
+	if namespace, _, ok := splitNamespace(expr); ok {
+		index = strings.Index(expr, namespace) + len(namespace) + 1
+	}
+
+	for index < len(expr) {
+		char := expr[index]
+
+		if char == ':' && index+1 < len(expr) && expr[index+1] == ':' {
+			index += 2
+
+			continue
+		}
+
+		if char == ':' || char == '^' || char == ',' {
+			break
+		}
+
+		index++
+	}
+
+	name := strings.TrimSpace(expr[:index])
+	rest := expr[index:]
+
+	for _, op := range []string{opDefault, opRequired, opAlternate, opUpperAll, opLowerAll} {
+		if strings.HasPrefix(rest, op) {
+			return name, op, rest[len(op):]
+		}
+	}
+
+	for _, op := range []string{opSubstring, opUpper, opLower} {
+		if strings.HasPrefix(rest, op) {
+			return name, op, rest[len(op):]
+		}
+	}
+
+	return name, "", rest
+}
+
+// parseListPlaceholder splits the body of a $[...] placeholder into the key and the optional separator given after
+// '|', as in $[key|;]. The separator is empty when none is given.
+func parseListPlaceholder(expr string) (string, string) {
+	key, sep, ok := strings.Cut(expr, "|")
+	if !ok {
+		return strings.TrimSpace(expr), ""
+	}
+
+	return strings.TrimSpace(key), sep
+}
+
+// splitNamespace splits an env:, ini: or self: namespace from key. Namespaces are lowercase, and the text after the
+// ':' must not start an operator, so that ${ENV:-dev} still names the key ENV with a default. The bool is false
+// when key has no namespace.
+func splitNamespace(key string) (string, string, bool) {
+	trimKey := strings.TrimSpace(key)
+
+	index := strings.Index(trimKey, ":")
+	if index <= 0 || strings.HasPrefix(trimKey[index:], "::") {
+		return "", key, false
+	}
+
+	namespace := trimKey[:index]
+
+	switch namespace {
+	case NamespaceEnv, NamespaceIni, NamespaceSelf:
+	default:
+		return "", key, false
+	}
+
+	rest := trimKey[index+1:]
+	if rest == "" || strings.ContainsRune("-?+ ", rune(rest[0])) || (rest[0] >= '0' && rest[0] <= '9') {
+		return "", key, false
+	}
+
+	return namespace, strings.TrimSpace(rest), true
+}
+
+// DefaultSectionRef is the section name that, as in ${..::KEY}, forces a reference to the default section.
+const DefaultSectionRef = ".."
+
+// reference is one candidate key for a placeholder: the key to look up, the layers it may be read from, and the
+// name it takes in an interpolation chain.
+type reference struct {
+	key      string
+	sources  sourceMask
+	chainKey string
+}
+
+// parseReference returns the candidate keys named by a placeholder, in the order they are tried. env:KEY reads
+// only the environment and ini:KEY only INI data; both keep the namespace in the chain name. self:KEY names KEY in
+// the section of the value being expanded, the last key of chain. An unqualified KEY tries that section first and
+// then the default section, while ..::KEY always names the default section.
+func parseReference(key string, chain []string) []reference {
+	namespace, name, ok := splitNamespace(key)
+
+	name = strings.TrimSpace(name)
+
+	if strings.HasPrefix(name, DefaultSectionRef+"::") {
+		name = strings.TrimPrefix(name, DefaultSectionRef+"::")
+
+		if ok {
+			return []reference{newReference(namespace, name)}
+		}
+
+		return []reference{{key: name, sources: sourceAll, chainKey: name}}
+	}
+
+	if ok && namespace != NamespaceSelf {
+		return []reference{newReference(namespace, name)}
+	}
+
+	section := currentSection(chain)
+
+	if section == DefaultSection || strings.Contains(name, "::") {
+		return []reference{{key: name, sources: sourceAll, chainKey: name}}
+	}
+
+	sectionKey := section + "::" + name
+
+	if ok {
+		return []reference{{key: sectionKey, sources: sourceAll, chainKey: sectionKey}}
+	}
+
+	return []reference{
+		{key: sectionKey, sources: sourceAll, chainKey: sectionKey},
+		{key: name, sources: sourceAll, chainKey: name},
+	}
+}
+
+// newReference returns the reference for name read through an env: or ini: namespace.
+func newReference(namespace string, name string) reference {
+	if namespace == NamespaceEnv {
+		return reference{key: name, sources: sourceEnv, chainKey: NamespaceEnv + ":" + name}
+	}
+
+	if namespace == NamespaceIni {
+		return reference{key: name, sources: sourceIni, chainKey: NamespaceIni + ":" + name}
+	}
+
+	return reference{key: name, sources: sourceAll, chainKey: name}
+}
+
+// currentSection returns the section of the value being expanded, taken from the last key of chain, or
+// [DefaultSection] outside any expansion.
+func currentSection(chain []string) string {
+	if len(chain) == 0 {
+		return DefaultSection
+	}
+
+	// Chain keys are uppercased, so the namespace of ENV:DB::HOST is matched here regardless of case.
+	chainKey := chain[len(chain)-1]
+
+	if namespace, name, ok := strings.Cut(chainKey, ":"); ok && !strings.HasPrefix(name, ":") {
+		switch strings.ToLower(namespace) {
+		case NamespaceEnv, NamespaceIni, NamespaceSelf:
+			chainKey = name
+		}
+	}
+
+	section, _ := splitKey(chainKey)
+
+	return section
+}
+
+// resolvePlaceholder expands the body of a ${...} placeholder, applying its operator. Words of the :-, :? and :+
+// operators are themselves expanded, so ${A:-${B}} falls back to the value of B. A placeholder naming a registered
+// function, as in ${upper:KEY}, calls the function instead (see [RegisterFunc]).
+func (p *ConfData) resolvePlaceholder(expr string, chain []string) ([]string, error) {
+	if _, _, ok := splitNamespace(expr); !ok {
+		if name, fn, rawArgs, ok := parseFuncCall(expr); ok {
+			return p.callFunc(name, fn, rawArgs, chain)
+		}
+	}
+
+	name, op, word := parsePlaceholder(expr)
+	if name == "" || (op == "" && word != "") {
+		return nil, newInterpolationError(chain, expr, fmt.Errorf("%w: ${%s}", ErrInvalidPlaceholder, expr))
+	}
+
+	vals, ok, err := p.resolve(name, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	isEmpty := !ok || len(vals) == 0 || (len(vals) == 1 && vals[0] == "")
+
+	switch op {
+	case opDefault:
+		if isEmpty {
+			return p.analysisValue(word, chain)
+		}
+
+		return vals, nil
+	case opAlternate:
+		if isEmpty {
+			return []string{""}, nil
+		}
+
+		return p.analysisValue(word, chain)
+	case opRequired:
+		if !isEmpty {
+			return vals, nil
+		}
+
+		msgs, err := p.analysisValue(word, chain)
+		if err != nil {
+			return nil, err
+		}
+
+		msg := strings.Join(msgs, DefaultStringsSeparator)
+		if msg == "" {
+			msg = fmt.Sprintf("%s is not set", name)
+		}
+
+		return nil, newInterpolationError(chain, name, fmt.Errorf("%w: %s", ErrValueRequired, msg))
+	}
+
+	if !ok {
+		if fn, ok := getFunc(name); ok && op == "" {
+			return p.callFunc(name, fn, "", chain)
+		}
+
+		return nil, newInterpolationError(chain, name, &KeyNotFoundError{Key: name})
+	}
+
+	if op == "" {
+		return vals, nil
+	}
+
+	transform, err := placeholderTransform(op, word)
+	if err != nil {
+		return nil, newInterpolationError(chain, name, err)
+	}
+
+	rets := make([]string, 0, len(vals))
+
+	for _, val := range vals {
+		rets = append(rets, transform(val))
+	}
+
+	return rets, nil
+}
+
+// placeholderTransform returns the function applying a substring or case operator to a value.
+func placeholderTransform(op string, word string) (func(string) string, error) {
+	switch op {
+	case opUpperAll:
+		return strings.ToUpper, nil
+	case opLowerAll:
+		return strings.ToLower, nil
+	case opUpper:
+		return func(val string) string {
+			return mapFirstRune(val, unicode.ToUpper)
+		}, nil
+	case opLower:
+		return func(val string) string {
+			return mapFirstRune(val, unicode.ToLower)
+		}, nil
+	}
+
+	// opSubstring: offset[:length], where a negative offset counts from the end and a negative length
+	// stops that many characters before the end.
+	params := strings.SplitN(word, ":", 2)
+
+	offset, err := strconv.Atoi(strings.TrimSpace(params[0]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid substring offset %q", ErrInvalidPlaceholder, params[0])
+	}
+
+	hasLength := len(params) == 2
+
+	length := 0
+	if hasLength {
+		length, err = strconv.Atoi(strings.TrimSpace(params[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid substring length %q", ErrInvalidPlaceholder, params[1])
+		}
+	}
+
+	return func(val string) string {
+		runes := []rune(val)
+
+		start := offset
+		if start < 0 {
+			start += len(runes)
+		}
+
+		start = max(0, min(start, len(runes)))
+
+		end := len(runes)
+		if hasLength {
+			if length < 0 {
+				end = len(runes) + length
+			} else {
+				end = start + length
+			}
+		}
+
+		end = max(start, min(end, len(runes)))
+
+		return string(runes[start:end])
+	}, nil
+}
+
+// mapFirstRune returns val with mapping applied to its first character.
+func mapFirstRune(val string, mapping func(rune) rune) string {
+	char, size := utf8.DecodeRuneInString(val)
+	if size == 0 {
+		return val
+	}
+
+	return string(mapping(char)) + val[size:]
+}
@@ -0,0 +1,128 @@
+package tcfg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlaceholderOperators(t *testing.T) {
+	src := "NAME = orders\nUP = ORDERS\nEMPTY =\n"
+
+	tests := []struct {
+		val string
+
+		want    string
+		wantErr error
+	}{
+		{val: "${MISSING:-def}", want: "def"},
+		{val: "${EMPTY:-def}", want: "def"},
+		{val: "${NAME:-def}", want: "orders"},
+		{val: "${MISSING:-${NAME}}", want: "orders"},
+		{val: "${NAME:-a}b", want: "ordersb"},
+		{val: "${NAME:+set}", want: "set"},
+		{val: "${MISSING:+set}", want: ""},
+		{val: "${MISSING:?must be set}", wantErr: ErrValueRequired},
+		{val: "${EMPTY:?must be set}", wantErr: ErrValueRequired},
+		{val: "${NAME:1}", want: "rders"},
+		{val: "${NAME:1:3}", want: "rde"},
+		{val: "${NAME: -3}", want: "ers"},
+		{val: "${NAME:x}", wantErr: ErrInvalidPlaceholder},
+		{val: "${NAME:1:x}", wantErr: ErrInvalidPlaceholder},
+		{val: "${NAME^^}", want: "ORDERS"},
+		{val: "${NAME^}", want: "Orders"},
+		{val: "${UP,,}", want: "orders"},
+		{val: "${UP,}", want: "oRDERS"},
+		{val: "$${NAME:-def}", want: "${NAME:-def}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			confData := newConfData(t, src+"V = "+tt.val+"\n", nil)
+
+			val, err := confData.String("V")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && val != tt.want) {
+				t.Errorf("String(V) = %q, %v, want %q, %v", val, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlaceholderNamespaces(t *testing.T) {
+	src := "HOST = ini.host\n[DB]\nPORT = 5432\nHOST = db.host\n"
+	env := map[string]string{"HOST": "env.host", "ONLY": "e", "PORT_DB": "6543", "ENV": "prod"}
+
+	tests := []struct {
+		val string
+
+		want    string
+		wantErr error
+	}{
+		{val: "${env:HOST}", want: "env.host"},
+		{val: "${ini:HOST}", want: "ini.host"},
+		{val: "${env:ONLY}", want: "e"},
+		{val: "${ini:ONLY}", wantErr: ErrKeyNotFound},
+		{val: "${env:DB::PORT}", want: "6543"},
+		{val: "${ini:DB::PORT}", want: "5432"},
+		{val: "${self:HOST}", want: "env.host"},
+		{val: "${env:MISSING:-d}", want: "d"},
+		{val: "${ENV:-dev}", want: "prod"},
+		{val: "${ENV:+set}", want: "set"},
+		{val: "${Env:HOST}", wantErr: ErrInvalidPlaceholder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			confData := newConfData(t, "V = "+tt.val+"\n"+src, env)
+
+			val, err := confData.String("V")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && val != tt.want) {
+				t.Errorf("String(V) = %q, %v, want %q, %v", val, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSectionRelativeReferences(t *testing.T) {
+	confData := newConfData(t, `
+HOST = h0
+PORT = 1
+NAME = n0
+
+[DB]
+HOST = h1
+URL = ${HOST}:${PORT}
+TOP = ${..::HOST}
+SELF = ${self:PORT:-none}
+FULL = ${DB::HOST}
+
+[DB_PROD : DB]
+HOST = h2
+
+[A.B]
+HOST = ab
+URL = ${HOST}-${NAME}
+`, map[string]string{"PORT_DB": "9"})
+
+	tests := []struct {
+		key string
+
+		want string
+	}{
+		{key: "DB::URL", want: "h1:9"},
+		{key: "DB::TOP", want: "h0"},
+		{key: "DB::SELF", want: "9"},
+		{key: "DB::FULL", want: "h1"},
+		{key: "DB_PROD::URL", want: "h2:1"},
+		{key: "DB_PROD::SELF", want: "none"},
+		{key: "A.B::URL", want: "ab-n0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, err := confData.String(tt.key)
+			if err != nil || val != tt.want {
+				t.Errorf("String(%q) = %q, %v, want %q", tt.key, val, err, tt.want)
+			}
+		})
+	}
+}
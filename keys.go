@@ -0,0 +1,113 @@
+package tcfg
+
+import (
+	"iter"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Keys returns the keys configured in p, in sorted order and in the form accepted by the getters: the keys of the
+// INI layers, together with the keys of variables that the environment mapper places in an INI section. When a key
+// prefix is set, only keys starting with it are listed, without the prefix, and variables under the prefix are
+// listed even when no INI key matches them. A view created by [ConfData.Sub] lists the keys of its section, relative
+// to it.
+func (p *ConfData) Keys() []string {
+	if p == nil {
+		return nil
+	}
+
+	iniLayers := p.getIniLayers()
+
+	p.mutex.RLock()
+	envData := p.envData
+	keyPrefix := p.keyPrefix
+	section := p.section
+	p.mutex.RUnlock()
+
+	keysMap := make(map[string]bool)
+
+	// addKey records key when its name starts with the key prefix, relative to the section of p.
+	addKey := func(key string) {
+		tmpSection, tmpKey, ok := cutKey(key)
+		if !ok {
+			tmpSection, tmpKey = "", key
+		}
+
+		if !strings.HasPrefix(tmpKey, keyPrefix) {
+			return
+		}
+
+		tmpKey = strings.TrimPrefix(tmpKey, keyPrefix)
+		if tmpKey == "" {
+			return
+		}
+
+		if section != "" {
+			switch {
+			case tmpSection == section:
+				tmpSection = ""
+			case strings.HasPrefix(tmpSection, section+SectionSeparator):
+				tmpSection = strings.TrimPrefix(tmpSection, section+SectionSeparator)
+			default:
+				return
+			}
+		}
+
+		if tmpSection == "" {
+			keysMap[tmpKey] = true
+		} else {
+			keysMap[tmpSection+"::"+tmpKey] = true
+		}
+	}
+
+	sections := make([]string, 0)
+
+	for _, iniData := range iniLayers {
+		for _, key := range iniData.Keys() {
+			addKey(key)
+		}
+
+		for _, tmpSection := range iniData.Sections() {
+			if !strings.Contains(tmpSection, ProfileSeparator) && !slices.Contains(sections, tmpSection) {
+				sections = append(sections, tmpSection)
+			}
+		}
+	}
+
+	if envData != nil {
+		for _, key := range envData.Keys(keyPrefix, sections...) {
+			// Without a key prefix a variable outside every section could be any part of the environment.
+			if _, _, ok := cutKey(key); ok || keyPrefix != "" {
+				addKey(key)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(keysMap))
+
+	for key := range keysMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// All returns an iterator over the keys listed by [ConfData.Keys] and their effective values, as returned by
+// [ConfData.String]. Keys whose values cannot be resolved, such as those with a broken reference, are skipped.
+func (p *ConfData) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, key := range p.Keys() {
+			val, ok, err := p.GetString(key)
+			if !ok || err != nil {
+				continue
+			}
+
+			if !yield(key, val) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package tcfg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	src := `
+NAME = orders
+MYAPP_HOST = h
+[DB : BASE]
+HOST = db
+[BASE]
+PORT = 5432
+[DB@PROD]
+USER = prod
+[SERVER.HTTP]
+MYAPP_PORT = 80
+`
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		keyPrefix string
+		sections  []string
+		profiles  []string
+
+		want []string
+	}{
+		{
+			name: "ini keys",
+			want: []string{"BASE::PORT", "DB::HOST", "DB::PORT", "MYAPP_HOST", "NAME", "SERVER.HTTP::MYAPP_PORT"},
+		},
+		{
+			name:     "profile keys",
+			profiles: []string{"prod"},
+			want: []string{"BASE::PORT", "DB::HOST", "DB::PORT", "DB::USER", "MYAPP_HOST", "NAME",
+				"SERVER.HTTP::MYAPP_PORT"},
+		},
+		{
+			name: "environment keys",
+			env:  map[string]string{"NAME_DB": "x", "PATH": "/bin"},
+			want: []string{"BASE::PORT", "DB::HOST", "DB::NAME", "DB::PORT", "MYAPP_HOST", "NAME",
+				"SERVER.HTTP::MYAPP_PORT"},
+		},
+		{
+			name:      "key prefix",
+			env:       map[string]string{"MYAPP_DEBUG": "1", "PATH": "/bin"},
+			keyPrefix: "MYAPP_",
+			want:      []string{"DEBUG", "HOST", "SERVER.HTTP::PORT"},
+		},
+		{
+			name:     "view",
+			env:      map[string]string{"NAME_SERVER_HTTP": "x"},
+			sections: []string{"server"},
+			want:     []string{"HTTP::MYAPP_PORT", "HTTP::NAME"},
+		},
+		{
+			name:      "view with a key prefix",
+			keyPrefix: "MYAPP_",
+			sections:  []string{"server", "http"},
+			want:      []string{"PORT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, tt.env, WithKeyPrefix(tt.keyPrefix))
+
+			confData.SetProfiles(tt.profiles...)
+
+			for _, section := range tt.sections {
+				confData = confData.Sub(section)
+			}
+
+			if keys := confData.Keys(); !slices.Equal(keys, tt.want) {
+				t.Errorf("Keys() = %q, want %q", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	confData := newConfData(t, "A = 1\nB = ${MISSING}\nC = ${A}2\n[DB]\nHOST = db\n", map[string]string{"A": "env"})
+
+	keys := make([]string, 0)
+	vals := make([]string, 0)
+
+	for key, val := range confData.All() {
+		keys = append(keys, key)
+		vals = append(vals, val)
+	}
+
+	if want := []string{"A", "C", "DB::HOST"}; !slices.Equal(keys, want) {
+		t.Errorf("All() keys = %q, want %q", keys, want)
+	}
+
+	if want := []string{"env", "env2", "db"}; !slices.Equal(vals, want) {
+		t.Errorf("All() values = %q, want %q", vals, want)
+	}
+
+	count := 0
+
+	for range confData.All() {
+		count++
+
+		break
+	}
+
+	if count != 1 {
+		t.Errorf("All() yielded %d keys after break, want 1", count)
+	}
+}
@@ -0,0 +1,548 @@
+package tcfg
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrorHook receives failures that Default* accessors would otherwise hide when strict defaults are enabled.
+// key is the key passed to the accessor and err describes why its value could not be used.
+type ErrorHook func(key string, err error)
+
+// LogErrorHook writes the failure to the standard logger. It is used when strict defaults are enabled without a hook.
+func LogErrorHook(key string, err error) {
+	log.Printf("tcfg: the value of %s is invalid and the default is used instead: %v", key, err)
+}
+
+// PanicErrorHook panics with an error naming key and wrapping err.
+func PanicErrorHook(key string, err error) {
+	panic(fmt.Errorf("tcfg: the value of %s is invalid: %w", key, err))
+}
+
+// Option configures a [ConfData] created by [New].
+type Option func(*ConfData)
+
+// WithIniData sets the INI layer consulted after the environment.
+func WithIniData(iniData *IniData) Option {
+	return func(p *ConfData) {
+		p.iniData = iniData
+	}
+}
+
+// WithRemoteSource sets a remote source whose configuration is layered over the INI data (see
+// [ConfData.SetRemoteSource]).
+func WithRemoteSource(remote *RemoteSource) Option {
+	return func(p *ConfData) {
+		p.remote = remote
+
+		remote.attach(p.sharedEpoch())
+	}
+}
+
+// WithEnvData sets the environment layer. [New] uses a live [EnvData] when this option is not given.
+func WithEnvData(envData *EnvData) Option {
+	return func(p *ConfData) {
+		p.envData = envData
+	}
+}
+
+// WithStrictDefaults sets whether Default* accessors report values that are present but invalid to the error hook
+// instead of silently using the default (see [ConfData.SetStrictDefaults]).
+func WithStrictDefaults(strictDefaults bool) Option {
+	return func(p *ConfData) {
+		p.strictDefaults = strictDefaults
+	}
+}
+
+// WithErrorHook sets the hook that receives invalid values when strict defaults are enabled.
+func WithErrorHook(errorHook ErrorHook) Option {
+	return func(p *ConfData) {
+		p.errorHook = errorHook
+	}
+}
+
+// WithMaxDepth sets the limit on nested references followed while expanding one value (see [ConfData.SetMaxDepth]).
+func WithMaxDepth(maxDepth int) Option {
+	return func(p *ConfData) {
+		p.maxDepth = maxDepth
+	}
+}
+
+// WithMaxExpansion sets the limit on alternatives one value may expand to (see [ConfData.SetMaxExpansion]).
+func WithMaxExpansion(maxExpansion int) Option {
+	return func(p *ConfData) {
+		p.maxExpansion = maxExpansion
+	}
+}
+
+// WithListSeparator sets the separator used to split and join $[] lists (see [ConfData.SetListSeparator]).
+func WithListSeparator(sep string) Option {
+	return func(p *ConfData) {
+		p.listSeparator = sep
+	}
+}
+
+// WithKeyPrefix sets the key prefix used when resolving keys (see [ConfData.SetKeyPrefix]).
+func WithKeyPrefix(keyPrefix string) Option {
+	return func(p *ConfData) {
+		p.keyPrefix = keyPrefix
+	}
+}
+
+// WithScopes sets the scope chain (see [ConfData.SetScopes]).
+func WithScopes(scopes ...string) Option {
+	return func(p *ConfData) {
+		p.scopes = append([]string{}, scopes...)
+	}
+}
+
+// New returns a [ConfData] configured by opts. Unlike the package-level instance it does not search for a
+// configuration file; use [WithIniData] to attach parsed INI data.
+func New(opts ...Option) (*ConfData, error) {
+	confData := &ConfData{
+		envData: &EnvData{},
+	}
+
+	for _, opt := range opts {
+		opt(confData)
+	}
+
+	return confData, nil
+}
+
+// SetStrictDefaults sets whether Default* accessors distinguish missing keys from invalid values. When enabled,
+// a missing key still yields the default, but a value that fails to parse or expand is also passed to the
+// error hook ([LogErrorHook] unless [ConfData.SetErrorHook] installed another) before the default is returned.
+func (p *ConfData) SetStrictDefaults(strictDefaults bool) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.strictDefaults = strictDefaults
+
+	p.mutex.Unlock()
+}
+
+// SetErrorHook sets the hook used by strict Default* accessors. A nil hook restores [LogErrorHook].
+func (p *ConfData) SetErrorHook(errorHook ErrorHook) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.errorHook = errorHook
+
+	p.mutex.Unlock()
+}
+
+// handleDefaultError passes err to the error hook when strict defaults are enabled and the key exists.
+func (p *ConfData) handleDefaultError(key string, ok bool, err error) {
+	if p == nil || !ok || err == nil {
+		return
+	}
+
+	p.mutex.RLock()
+	strictDefaults := p.strictDefaults
+	errorHook := p.errorHook
+	p.mutex.RUnlock()
+
+	if !strictDefaults {
+		return
+	}
+
+	if errorHook == nil {
+		errorHook = LogErrorHook
+	}
+
+	errorHook(key, err)
+}
+
+// SetMaxDepth sets the limit on nested ${} and $[] references followed while expanding one value. Exceeding it
+// yields an [*InterpolationError] wrapping [ErrInterpolationDepth]. A value below one restores [DefaultMaxDepth].
+func (p *ConfData) SetMaxDepth(maxDepth int) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.maxDepth = maxDepth
+
+	p.mutex.Unlock()
+
+	p.clearCache()
+}
+
+// getMaxDepth returns the configured maximum depth, or [DefaultMaxDepth] when none is set.
+func (p *ConfData) getMaxDepth() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.maxDepth < 1 {
+		return DefaultMaxDepth
+	}
+
+	return p.maxDepth
+}
+
+// SetMaxExpansion sets the limit on the number of alternatives one value may expand to through the Cartesian
+// product of its $[] lists. Exceeding it yields an [*InterpolationError] wrapping [ErrExpansionLimit]. A value below
+// one restores [DefaultMaxExpansion].
+func (p *ConfData) SetMaxExpansion(maxExpansion int) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.maxExpansion = maxExpansion
+
+	p.mutex.Unlock()
+
+	p.clearCache()
+}
+
+// getMaxExpansion returns the configured maximum expansion, or [DefaultMaxExpansion] when none is set.
+func (p *ConfData) getMaxExpansion() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.maxExpansion < 1 {
+		return DefaultMaxExpansion
+	}
+
+	return p.maxExpansion
+}
+
+// SetListSeparator sets the separator used to split $[key] lists held as scalar values and to join the alternatives
+// of an expanded value in [ConfData.GetString]. An empty separator restores [DefaultStringsSeparator]. A placeholder
+// may still choose its own separator, as in $[key|;].
+func (p *ConfData) SetListSeparator(sep string) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.listSeparator = sep
+
+	p.mutex.Unlock()
+
+	p.clearCache()
+}
+
+// getListSeparator returns the configured list separator, or [DefaultStringsSeparator] when none is set.
+func (p *ConfData) getListSeparator() string {
+	if p == nil {
+		return DefaultStringsSeparator
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.listSeparator == "" {
+		return DefaultStringsSeparator
+	}
+
+	return p.listSeparator
+}
+
+// SetEnvMapper sets the naming scheme the environment layer uses for SECTION::KEY keys (see [EnvData.SetMapper]).
+func (p *ConfData) SetEnvMapper(mapper EnvMapper) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	if p.envData == nil {
+		p.envData = &EnvData{}
+	}
+
+	envData := p.envData
+
+	p.mutex.Unlock()
+
+	envData.SetMapper(mapper)
+
+	p.clearCache()
+}
+
+// KeyPrefix returns the key prefix of p.
+func (p *ConfData) KeyPrefix() string {
+	if p == nil {
+		return ""
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.keyPrefix
+}
+
+// SetKeyPrefix sets the prefix prepended to keys when resolving them, so that with prefix MYAPP_ the key HOST is
+// read as MYAPP_HOST. Each ConfData has its own prefix; the package-level [SetKeyPrefix] sets the prefix of the
+// default instance.
+func (p *ConfData) SetKeyPrefix(keyPrefix string) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.keyPrefix = keyPrefix
+
+	p.mutex.Unlock()
+
+	p.clearCache()
+}
+
+// WithPrefix returns a view of p that resolves keys with keyPrefix. The view shares the INI and environment layers
+// of p and copies its other settings; later changes to the settings of either do not affect the other.
+func (p *ConfData) WithPrefix(keyPrefix string) *ConfData {
+	if p == nil {
+		return nil
+	}
+
+	confData := p.clone()
+	confData.keyPrefix = keyPrefix
+
+	return confData
+}
+
+// Sub returns a view of p scoped to section and its nested sections. Keys read through the view are relative to
+// section: with Sub("SERVER"), PORT reads SERVER::PORT and HTTP::PORT reads SERVER.HTTP::PORT, with the
+// environment overlay and interpolation applied as usual. Keys read with an env: or ini: namespace, or with ..::,
+// are not relative to section. Like [ConfData.WithPrefix] the view shares the layers of p.
+func (p *ConfData) Sub(section string) *ConfData {
+	if p == nil {
+		return nil
+	}
+
+	section = strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(section, "::", SectionSeparator)))
+
+	confData := p.clone()
+
+	if confData.section != "" && section != "" {
+		confData.section += SectionSeparator + section
+	} else if section != "" {
+		confData.section = section
+	}
+
+	return confData
+}
+
+// Sections returns views of the repeated sections of section, in index order: the blocks of [[UPSTREAM]] headers,
+// or sections named explicitly as [UPSTREAM.0], [UPSTREAM.1] and so on. Each view behaves like one returned by
+// [ConfData.Sub], so HOST read through the second view reads UPSTREAM.1::HOST and may be overridden from the
+// environment. It returns nil when there are no such sections.
+func (p *ConfData) Sections(section string) []*ConfData {
+	if p == nil {
+		return nil
+	}
+
+	section = strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(section, "::", SectionSeparator)))
+
+	iniLayers := p.getIniLayers()
+
+	p.mutex.RLock()
+	tmpSection := p.section
+	p.mutex.RUnlock()
+
+	if section == "" {
+		return nil
+	}
+
+	if tmpSection != "" {
+		tmpSection += SectionSeparator + section
+	} else {
+		tmpSection = section
+	}
+
+	indices := make([]int, 0)
+
+	for _, iniData := range iniLayers {
+		iniData.RLock()
+		tmpIndices := iniData.sectionIndices(tmpSection)
+		iniData.RUnlock()
+
+		for _, index := range tmpIndices {
+			if !slices.Contains(indices, index) {
+				indices = append(indices, index)
+			}
+		}
+	}
+
+	slices.Sort(indices)
+
+	if len(indices) == 0 {
+		return nil
+	}
+
+	confDatas := make([]*ConfData, 0, len(indices))
+
+	for _, index := range indices {
+		confDatas = append(confDatas, p.Sub(section+SectionSeparator+strconv.Itoa(index)))
+	}
+
+	return confDatas
+}
+
+// Section returns the section a view created by [ConfData.Sub] is scoped to, or an empty string for the root.
+func (p *ConfData) Section() string {
+	if p == nil {
+		return ""
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.section
+}
+
+// clone returns a copy of p sharing its layers, with an empty cache when caching is enabled. The copy shares the
+// epoch of p, so clearing the cache of either discards the cached values of both.
+func (p *ConfData) clone() *ConfData {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	confData := &ConfData{
+		iniData: p.iniData,
+		remote:  p.remote,
+		envData: p.envData,
+
+		strictDefaults: p.strictDefaults,
+		errorHook:      p.errorHook,
+
+		maxDepth:     p.maxDepth,
+		maxExpansion: p.maxExpansion,
+
+		listSeparator: p.listSeparator,
+
+		templateMode: p.templateMode,
+
+		keyPrefix: p.keyPrefix,
+		scopes:    p.scopes,
+
+		section: p.section,
+	}
+
+	confData.epoch = p.sharedEpoch()
+
+	if p.cache != nil {
+		confData.cache = newValueCache(confData.epoch)
+	}
+
+	return confData
+}
+
+// qualifyKey returns key, read through a view created by [ConfData.Sub], relative to the root. Keys naming the
+// default section with ..:: are left as they are, and so are env: and ini: keys, which name a variable or an INI
+// key directly; self: keys name a key of the view's section.
+func (p *ConfData) qualifyKey(key string) string {
+	section := p.Section()
+	if section == "" {
+		return key
+	}
+
+	if namespace, name, ok := splitNamespace(key); ok {
+		if namespace != NamespaceSelf {
+			return key
+		}
+
+		return namespace + ":" + p.qualifyKey(name)
+	}
+
+	key = strings.TrimSpace(key)
+
+	if strings.HasPrefix(key, DefaultSectionRef+"::") {
+		return key
+	}
+
+	if tmpSection, tmpKey, ok := cutKey(key); ok {
+		return section + SectionSeparator + strings.ToUpper(tmpSection) + "::" + tmpKey
+	}
+
+	return section + "::" + key
+}
+
+// Scopes returns the names of the keys forming the scope chain, most general first.
+func (p *ConfData) Scopes() []string {
+	if p == nil {
+		return nil
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.scopes == nil {
+		return append([]string{}, DefaultScopes...)
+	}
+
+	return append([]string{}, p.scopes...)
+}
+
+// SetScopes sets the scope chain, most general first, such as APP_NAME, DEPLOY_ENV, REGION, HOSTNAME. The values
+// of these keys qualify keys in [ConfData.LocalKey], and a qualified key falls back one scope at a time, so
+// ORDERS_PROD_EU_DB_HOST is tried before ORDERS_PROD_DB_HOST, ORDERS_DB_HOST and DB_HOST. Unset scopes are
+// skipped. Calling SetScopes without names disables scoping.
+func (p *ConfData) SetScopes(scopes ...string) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.scopes = append([]string{}, scopes...)
+
+	p.mutex.Unlock()
+
+	p.clearCache()
+}
+
+// SetRemoteSource sets a remote source whose configuration, once loaded, is looked up before the INI data of p.
+// Keys missing from the remote configuration are still read from the INI data, and the environment takes
+// precedence over both. A nil source removes it.
+func (p *ConfData) SetRemoteSource(remote *RemoteSource) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.remote = remote
+	epoch := p.sharedEpoch()
+
+	p.mutex.Unlock()
+
+	remote.attach(epoch)
+
+	p.clearCache()
+}
+
+// SetProfiles sets the active INI profiles, lowest priority first (see [IniData.SetProfiles]), in the INI data and
+// in the configuration of the remote source, including configurations it loads later.
+func (p *ConfData) SetProfiles(profiles ...string) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.RLock()
+	remote := p.remote
+	iniData := p.iniData
+	p.mutex.RUnlock()
+
+	if remote != nil {
+		remote.setProfiles(profiles...)
+	}
+
+	if iniData != nil {
+		iniData.SetProfiles(profiles...)
+	}
+}
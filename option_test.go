@@ -0,0 +1,477 @@
+package tcfg
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStrictDefaults(t *testing.T) {
+	src := "PORT = 80a\nDEBUG = maybe\nTIMEOUT = 5s\n"
+
+	tests := []struct {
+		name   string
+		strict bool
+		get    func(confData *ConfData) any
+
+		want     any
+		wantKeys []string
+	}{
+		{
+			name:     "invalid int",
+			strict:   true,
+			get:      func(confData *ConfData) any { return confData.DefaultInt("PORT", 8080) },
+			want:     8080,
+			wantKeys: []string{"PORT"},
+		},
+		{
+			name:     "invalid bool",
+			strict:   true,
+			get:      func(confData *ConfData) any { return confData.DefaultBool("DEBUG", true) },
+			want:     true,
+			wantKeys: []string{"DEBUG"},
+		},
+		{
+			name:   "missing key",
+			strict: true,
+			get:    func(confData *ConfData) any { return confData.DefaultInt("MISSING", 1) },
+			want:   1,
+		},
+		{
+			name:   "valid value",
+			strict: true,
+			get:    func(confData *ConfData) any { return confData.DefaultDuration("TIMEOUT", time.Second) },
+			want:   5 * time.Second,
+		},
+		{
+			name:   "lenient",
+			strict: false,
+			get:    func(confData *ConfData) any { return confData.DefaultInt("PORT", 8080) },
+			want:   8080,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+
+			confData := newConfData(t, src, nil, WithStrictDefaults(tt.strict), WithErrorHook(func(key string, err error) {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Errorf("hook error = %v, want a *ParseError", err)
+				}
+
+				keys = append(keys, key)
+			}))
+
+			if got := tt.get(confData); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("hook keys = %q, want %q", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestPanicErrorHook(t *testing.T) {
+	confData := newConfData(t, "PORT = 80a\n", nil)
+
+	confData.SetStrictDefaults(true)
+	confData.SetErrorHook(PanicErrorHook)
+
+	defer func() {
+		err, ok := recover().(error)
+
+		var parseErr *ParseError
+		if !ok || !errors.As(err, &parseErr) {
+			t.Errorf("recover() = %v, want an error wrapping a *ParseError", err)
+		}
+	}()
+
+	confData.DefaultInt("PORT", 8080)
+}
+
+func TestKeyPrefix(t *testing.T) {
+	src := "HOST = plain\nMYAPP_HOST = prefixed\nMYAPP_URL = http://${HOST}\n[DB]\nMYAPP_HOST = db\n"
+
+	tests := []struct {
+		name      string
+		keyPrefix string
+		env       map[string]string
+		key       string
+
+		want string
+	}{
+		{name: "no prefix", key: "HOST", want: "plain"},
+		{name: "prefix prepended", keyPrefix: "MYAPP_", key: "HOST", want: "prefixed"},
+		{name: "prefix already present", keyPrefix: "MYAPP_", key: "MYAPP_HOST", want: "prefixed"},
+		{name: "section key", keyPrefix: "MYAPP_", key: "DB::HOST", want: "db"},
+		{name: "interpolation", keyPrefix: "MYAPP_", key: "URL", want: "http://prefixed"},
+		{
+			name:      "environment",
+			keyPrefix: "MYAPP_",
+			env:       map[string]string{"MYAPP_HOST": "env"},
+			key:       "HOST",
+			want:      "env",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, tt.env, WithKeyPrefix(tt.keyPrefix))
+
+			val, err := confData.String(tt.key)
+			if err != nil || val != tt.want {
+				t.Errorf("String(%q) = %q, %v, want %q", tt.key, val, err, tt.want)
+			}
+
+			confData = newConfData(t, src, tt.env)
+			confData.SetKeyPrefix(tt.keyPrefix)
+
+			val, err = confData.String(tt.key)
+			if err != nil || val != tt.want {
+				t.Errorf("SetKeyPrefix: String(%q) = %q, %v, want %q", tt.key, val, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	confData := newConfData(t, "HOST = plain\nA_HOST = a\nB_HOST = b\n", nil, WithCache(true))
+
+	viewA := confData.WithPrefix("A_")
+	viewB := confData.WithPrefix("B_")
+
+	tests := []struct {
+		name     string
+		confData *ConfData
+
+		wantPrefix string
+		want       string
+	}{
+		{name: "root", confData: confData, want: "plain"},
+		{name: "view A", confData: viewA, wantPrefix: "A_", want: "a"},
+		{name: "view B", confData: viewB, wantPrefix: "B_", want: "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if keyPrefix := tt.confData.KeyPrefix(); keyPrefix != tt.wantPrefix {
+				t.Errorf("KeyPrefix() = %q, want %q", keyPrefix, tt.wantPrefix)
+			}
+
+			val, err := tt.confData.String("HOST")
+			if err != nil || val != tt.want {
+				t.Errorf("String(HOST) = %q, %v, want %q", val, err, tt.want)
+			}
+		})
+	}
+
+	viewA.SetKeyPrefix("B_")
+
+	if val := viewA.DefaultString("HOST", ""); val != "b" {
+		t.Errorf("String(HOST) after SetKeyPrefix on the view = %q, want %q", val, "b")
+	}
+
+	if val := confData.DefaultString("HOST", ""); val != "plain" {
+		t.Errorf("String(HOST) on the root after SetKeyPrefix on a view = %q, want %q", val, "plain")
+	}
+}
+
+func TestScopes(t *testing.T) {
+	src := `
+APP_NAME = orders
+DEPLOY_ENV = prod
+DB_HOST = base
+ORDERS_DB_HOST = app
+ORDERS_PROD_DB_HOST = app-env
+ORDERS_PROD_EU_CACHE = app-env-region
+ORDERS_CACHE = app-cache
+`
+
+	tests := []struct {
+		name   string
+		scopes []string
+		env    map[string]string
+		key    string
+
+		want         string
+		wantLocalKey string
+	}{
+		{
+			name:         "default scopes",
+			key:          "ORDERS_DB_HOST",
+			want:         "app",
+			wantLocalKey: "ORDERS_DB_HOST",
+		},
+		{
+			name:         "most specific",
+			scopes:       []string{"APP_NAME", "DEPLOY_ENV"},
+			key:          "ORDERS_PROD_DB_HOST",
+			want:         "app-env",
+			wantLocalKey: "ORDERS_PROD_DB_HOST",
+		},
+		{
+			name:         "fallback one scope at a time",
+			scopes:       []string{"APP_NAME", "DEPLOY_ENV"},
+			key:          "ORDERS_PROD_CACHE",
+			want:         "app-cache",
+			wantLocalKey: "ORDERS_PROD_DB_HOST",
+		},
+		{
+			name:         "unset scope skipped",
+			scopes:       []string{"APP_NAME", "REGION", "DEPLOY_ENV"},
+			key:          "ORDERS_PROD_DB_HOST",
+			want:         "app-env",
+			wantLocalKey: "ORDERS_PROD_DB_HOST",
+		},
+		{
+			name:         "scope from the environment",
+			scopes:       []string{"APP_NAME", "DEPLOY_ENV", "REGION"},
+			env:          map[string]string{"REGION": "eu"},
+			key:          "ORDERS_PROD_EU_DB_HOST",
+			want:         "app-env",
+			wantLocalKey: "ORDERS_PROD_EU_DB_HOST",
+		},
+		{
+			name:         "scoping disabled",
+			scopes:       []string{},
+			key:          "ORDERS_DB_HOST",
+			want:         "app",
+			wantLocalKey: "DB_HOST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{}
+			if tt.scopes != nil {
+				opts = append(opts, WithScopes(tt.scopes...))
+			}
+
+			confData := newConfData(t, src, tt.env, opts...)
+
+			val, err := confData.String(tt.key)
+			if err != nil || val != tt.want {
+				t.Errorf("String(%q) = %q, %v, want %q", tt.key, val, err, tt.want)
+			}
+
+			if localKey := confData.LocalKey("DB_HOST"); localKey != tt.wantLocalKey {
+				t.Errorf("LocalKey(DB_HOST) = %q, want %q", localKey, tt.wantLocalKey)
+			}
+		})
+	}
+}
+
+func TestSetScopes(t *testing.T) {
+	confData := newConfData(t, "APP_NAME = orders\nDEPLOY_ENV = prod\nORDERS_PROD_HOST = env\nORDERS_HOST = app\n", nil)
+
+	tests := []struct {
+		name   string
+		scopes []string
+
+		wantScopes []string
+		want       string
+		wantErr    error
+	}{
+		{name: "default", wantScopes: DefaultScopes, want: "app"},
+		{
+			name:       "two scopes",
+			scopes:     []string{"APP_NAME", "DEPLOY_ENV"},
+			wantScopes: []string{"APP_NAME", "DEPLOY_ENV"},
+			want:       "env",
+		},
+		{name: "disabled", scopes: []string{}, wantScopes: []string{}, wantErr: ErrKeyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.scopes != nil {
+				confData.SetScopes(tt.scopes...)
+			}
+
+			if scopes := confData.Scopes(); !slices.Equal(scopes, tt.wantScopes) {
+				t.Errorf("Scopes() = %q, want %q", scopes, tt.wantScopes)
+			}
+
+			val, err := confData.String(confData.LocalKey("HOST"))
+			if !errors.Is(err, tt.wantErr) || val != tt.want {
+				t.Errorf("String(LocalKey(HOST)) = %q, %v, want %q, %v", val, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSub(t *testing.T) {
+	src := `
+APP_NAME = orders
+NAME = root
+PORT = 1
+[SERVER]
+PORT = 80
+URL = http://${HOST}:${PORT}
+HOST = ${..::NAME}.local
+MYAPP_PORT = 8080
+ORDERS_PORT = 8081
+[SERVER.HTTP]
+PORT = 81
+`
+	env := map[string]string{"PORT_SERVER_HTTP": "82", "DBH": "db.local"}
+
+	tests := []struct {
+		name      string
+		sections  []string
+		keyPrefix string
+		scopes    []string
+		key       string
+
+		wantSection string
+		want        string
+		wantErr     error
+	}{
+		{name: "key", sections: []string{"server"}, key: "PORT", wantSection: "SERVER", want: "80"},
+		{name: "nested key", sections: []string{"server"}, key: "HTTP::PORT", wantSection: "SERVER", want: "82"},
+		{name: "nested view", sections: []string{"server", "http"}, key: "PORT", wantSection: "SERVER.HTTP", want: "82"},
+		{name: "dotted view", sections: []string{"server::http"}, key: "PORT", wantSection: "SERVER.HTTP", want: "82"},
+		{
+			name:        "interpolation",
+			sections:    []string{"server"},
+			key:         "URL",
+			wantSection: "SERVER",
+			want:        "http://root.local:80",
+		},
+		{name: "default section", sections: []string{"server"}, key: "..::PORT", wantSection: "SERVER", want: "1"},
+		{name: "self", sections: []string{"server"}, key: "self:PORT", wantSection: "SERVER", want: "80"},
+		{name: "env namespace", sections: []string{"db"}, key: "env:DBH", wantSection: "DB", want: "db.local"},
+		{
+			name:        "ini namespace",
+			sections:    []string{"server"},
+			key:         "ini:SERVER.HTTP::PORT",
+			wantSection: "SERVER",
+			want:        "81",
+		},
+		{name: "missing key", sections: []string{"server"}, key: "NAME", wantSection: "SERVER", wantErr: ErrKeyNotFound},
+		{name: "prefix", sections: []string{"server"}, keyPrefix: "MYAPP_", key: "PORT", wantSection: "SERVER", want: "8080"},
+		{
+			name:        "scopes",
+			sections:    []string{"server"},
+			scopes:      []string{"APP_NAME"},
+			key:         "ORDERS_PORT",
+			wantSection: "SERVER",
+			want:        "8081",
+		},
+		{
+			name:        "scope fallback",
+			sections:    []string{"server"},
+			scopes:      []string{"APP_NAME"},
+			key:         "ORDERS_URL",
+			wantSection: "SERVER",
+			want:        "http://root.local:80",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, env)
+
+			view := confData
+
+			for _, section := range tt.sections {
+				view = view.Sub(section)
+			}
+
+			if tt.keyPrefix != "" {
+				view = view.WithPrefix(tt.keyPrefix)
+			}
+
+			if tt.scopes != nil {
+				view.SetScopes(tt.scopes...)
+			}
+
+			if section := view.Section(); section != tt.wantSection {
+				t.Errorf("Section() = %q, want %q", section, tt.wantSection)
+			}
+
+			val, err := view.String(tt.key)
+			if !errors.Is(err, tt.wantErr) || val != tt.want {
+				t.Errorf("String(%q) = %q, %v, want %q, %v", tt.key, val, err, tt.want, tt.wantErr)
+			}
+
+			if section := confData.Section(); section != "" {
+				t.Errorf("Section() of the root = %q, want empty", section)
+			}
+		})
+	}
+}
+
+func TestSections(t *testing.T) {
+	src := `
+[[UPSTREAM]]
+HOST = a
+[[UPSTREAM]]
+HOST = b
+[UPSTREAM.5]
+HOST = c
+[SERVER]
+PORT = 80
+[[SERVER.LISTEN]]
+ADDR = :80
+[[SERVER.LISTEN]]
+ADDR = :443
+`
+	env := map[string]string{"HOST_UPSTREAM_1": "env"}
+
+	tests := []struct {
+		name     string
+		sections []string
+		section  string
+		key      string
+
+		wantSections []string
+		want         []string
+	}{
+		{
+			name:         "blocks",
+			section:      "upstream",
+			key:          "HOST",
+			wantSections: []string{"UPSTREAM.0", "UPSTREAM.1", "UPSTREAM.5"},
+			want:         []string{"a", "env", "c"},
+		},
+		{
+			name:         "in a view",
+			sections:     []string{"server"},
+			section:      "LISTEN",
+			key:          "ADDR",
+			wantSections: []string{"SERVER.LISTEN.0", "SERVER.LISTEN.1"},
+			want:         []string{":80", ":443"},
+		},
+		{name: "plain section", section: "SERVER"},
+		{name: "missing section", section: "MISSING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, env)
+
+			for _, section := range tt.sections {
+				confData = confData.Sub(section)
+			}
+
+			var sections []string
+			var vals []string
+
+			for _, view := range confData.Sections(tt.section) {
+				sections = append(sections, view.Section())
+				vals = append(vals, view.DefaultString(tt.key, ""))
+			}
+
+			if !slices.Equal(sections, tt.wantSections) || !slices.Equal(vals, tt.want) {
+				t.Errorf("Sections(%q) = %q with %q, want %q with %q", tt.section, sections, vals, tt.wantSections,
+					tt.want)
+			}
+		})
+	}
+}
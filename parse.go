@@ -0,0 +1,128 @@
+package tcfg
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// byteSizeUnits maps the supported size suffixes to their multipliers. Single-letter and *iB suffixes are binary
+// (powers of 1024); two-letter *B suffixes are decimal (powers of 1000).
+var byteSizeUnits = map[string]uint64{
+	"":  1,
+	"B": 1,
+
+	"K":   1 << 10,
+	"KIB": 1 << 10,
+	"KB":  1000,
+
+	"M":   1 << 20,
+	"MIB": 1 << 20,
+	"MB":  1000 * 1000,
+
+	"G":   1 << 30,
+	"GIB": 1 << 30,
+	"GB":  1000 * 1000 * 1000,
+
+	"T":   1 << 40,
+	"TIB": 1 << 40,
+	"TB":  1000 * 1000 * 1000 * 1000,
+
+	"P":   1 << 50,
+	"PIB": 1 << 50,
+	"PB":  1000 * 1000 * 1000 * 1000 * 1000,
+}
+
+// parseBytes parses a human-readable byte size such as 512MiB, 1.5GB or 4096. Suffixes are case-insensitive.
+func parseBytes(val string) (uint64, error) {
+	val = strings.TrimSpace(val)
+
+	index := strings.IndexFunc(val, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if index == -1 {
+		index = len(val)
+	}
+
+	number := val[:index]
+	unit := strings.ToUpper(strings.TrimSpace(val[index:]))
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("tcfg: invalid byte size: %q", val)
+	}
+
+	if !strings.Contains(number, ".") {
+		ret, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("tcfg: invalid byte size: %q", val)
+		}
+
+		if ret > (1<<64-1)/multiplier {
+			return 0, fmt.Errorf("tcfg: byte size out of range: %q", val)
+		}
+
+		return ret * multiplier, nil
+	}
+
+	ret, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("tcfg: invalid byte size: %q", val)
+	}
+
+	size := ret * float64(multiplier)
+	if size >= 1<<64 {
+		return 0, fmt.Errorf("tcfg: byte size out of range: %q", val)
+	}
+
+	return uint64(size), nil
+}
+
+// parseHostPort validates a host:port pair and returns it in canonical form. The port must be a number in the range 0-65535.
+func parseHostPort(val string) (string, error) {
+	host, port, err := net.SplitHostPort(val)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return "", fmt.Errorf("tcfg: invalid port in address %q", val)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// parseFileMode parses an octal permission string such as 0644 or 755.
+func parseFileMode(val string) (os.FileMode, error) {
+	ret, err := strconv.ParseUint(strings.TrimPrefix(val, "0o"), 8, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return os.FileMode(ret), nil
+}
+
+// parseInts splits val using sep and parses each trimmed element as a base-10 integer. An empty value yields an empty slice.
+func parseInts(val string, sep string) ([]int, error) {
+	if val == "" {
+		return []int{}, nil
+	}
+
+	vals := strings.Split(val, sep)
+
+	rets := make([]int, 0, len(vals))
+
+	for _, val := range vals {
+		ret, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			return nil, err
+		}
+
+		rets = append(rets, ret)
+	}
+
+	return rets, nil
+}
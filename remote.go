@@ -0,0 +1,398 @@
+package tcfg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPollInterval is the interval between polls of a [RemoteSource] unless [WithPollInterval] sets another.
+const (
+	DefaultPollInterval = 30 * time.Second
+)
+
+// DefaultMaxBackoff is the longest delay between polls after failures unless [WithMaxBackoff] sets another.
+const (
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// RemoteSource fetches configuration from a config service that answers with the [Response] JSON envelope, and
+// parses its configs with [IniMgr.ParseConfig]. Attached to a [ConfData] with [WithRemoteSource] it is layered
+// over the INI data, which keeps supplying the keys the service does not send, and below the environment.
+// [RemoteSource.Start] polls the service with If-None-Match so that unchanged configuration costs a 304, backing
+// off after failures, and a cache file keeps the last good response for starting while the service is down.
+type RemoteSource struct {
+	url string
+
+	client *http.Client
+
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+
+	cacheFile string
+
+	errorHook func(err error)
+	onChange  []func(iniData *IniData)
+
+	iniData  *IniData
+	etag     string
+	profiles []string
+
+	// epochs are the cache epochs of the ConfData values the source is attached to.
+	epochs []*atomic.Uint64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	sync.RWMutex
+}
+
+// RemoteOption configures a [RemoteSource] created by [NewRemoteSource].
+type RemoteOption func(*RemoteSource)
+
+// WithHTTPClient sets the client used for requests. [http.DefaultClient] is used when this option is not given.
+func WithHTTPClient(client *http.Client) RemoteOption {
+	return func(p *RemoteSource) {
+		p.client = client
+	}
+}
+
+// WithPollInterval sets the interval between polls (see [DefaultPollInterval]).
+func WithPollInterval(pollInterval time.Duration) RemoteOption {
+	return func(p *RemoteSource) {
+		p.pollInterval = pollInterval
+	}
+}
+
+// WithMaxBackoff sets the longest delay between polls after failures (see [DefaultMaxBackoff]).
+func WithMaxBackoff(maxBackoff time.Duration) RemoteOption {
+	return func(p *RemoteSource) {
+		p.maxBackoff = maxBackoff
+	}
+}
+
+// WithCacheFile sets the file that keeps the last good response. [RemoteSource.Load] reads it when the service
+// cannot be reached.
+func WithCacheFile(cacheFile string) RemoteOption {
+	return func(p *RemoteSource) {
+		p.cacheFile = cacheFile
+	}
+}
+
+// WithRemoteErrorHook sets the function that receives the failures of background polls and of writes to the cache
+// file. They are written to the standard logger when this option is not given.
+func WithRemoteErrorHook(errorHook func(err error)) RemoteOption {
+	return func(p *RemoteSource) {
+		p.errorHook = errorHook
+	}
+}
+
+// WithOnChange registers fn to be called with the new configuration after each change (see [RemoteSource.OnChange]).
+func WithOnChange(fn func(iniData *IniData)) RemoteOption {
+	return func(p *RemoteSource) {
+		p.onChange = append(p.onChange, fn)
+	}
+}
+
+// NewRemoteSource returns a RemoteSource for the config service at url. It does not contact the service; call
+// [RemoteSource.Load] or [RemoteSource.Start].
+func NewRemoteSource(url string, opts ...RemoteOption) *RemoteSource {
+	remoteSource := &RemoteSource{
+		url: url,
+	}
+
+	for _, opt := range opts {
+		opt(remoteSource)
+	}
+
+	return remoteSource
+}
+
+// IniData returns the configuration last loaded, or nil before the first successful load.
+func (p *RemoteSource) IniData() *IniData {
+	if p == nil {
+		return nil
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.iniData
+}
+
+// OnChange registers fn to be called with the new configuration after each change. Caches of the [ConfData] values
+// the source is attached to are discarded before fn is called, so fn may read the new values.
+func (p *RemoteSource) OnChange(fn func(iniData *IniData)) {
+	p.Lock()
+
+	p.onChange = append(p.onChange, fn)
+
+	p.Unlock()
+}
+
+// Fetch requests the configuration once and reports whether it changed. A 304 Not Modified answer to the
+// If-None-Match of the last response leaves the configuration unchanged. A successful response is written to the
+// cache file when one is set; a failure to write it is passed to the error hook and does not fail Fetch.
+func (p *RemoteSource) Fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	p.RLock()
+	etag := p.etag
+	p.RUnlock()
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	client := p.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, &RemoteStatusError{
+			URL:        p.url,
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	iniData, err := (&IniMgr{}).ParseResponse(bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+
+	p.update(iniData, resp.Header.Get("ETag"))
+
+	if p.cacheFile != "" {
+		err = writeCacheFile(p.cacheFile, data)
+		if err != nil {
+			p.handleError(fmt.Errorf("tcfg: cannot write the cache file %s: %w", p.cacheFile, err))
+		}
+	}
+
+	return true, nil
+}
+
+// Load fetches the configuration once. When the service cannot be reached or answers with an error and a cache
+// file is set, the configuration is read from the cache file instead; the error is returned only when neither
+// yields a configuration.
+func (p *RemoteSource) Load(ctx context.Context) error {
+	_, err := p.Fetch(ctx)
+	if err == nil || p.cacheFile == "" || p.IniData() != nil {
+		return err
+	}
+
+	data, cacheErr := os.ReadFile(p.cacheFile)
+	if cacheErr != nil {
+		return err
+	}
+
+	iniData, cacheErr := (&IniMgr{}).ParseResponse(bytes.NewReader(data))
+	if cacheErr != nil {
+		return err
+	}
+
+	p.update(iniData, "")
+
+	return nil
+}
+
+// Start polls the service in the background until ctx is done or [RemoteSource.Stop] is called. After a failure
+// the delay doubles up to the maximum backoff, and the first success restores the poll interval. Failures are
+// passed to the error hook. Calling Start again restarts polling.
+func (p *RemoteSource) Start(ctx context.Context) {
+	p.Stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	p.Lock()
+
+	p.cancel = cancel
+	p.done = done
+
+	p.Unlock()
+
+	go p.poll(ctx, done)
+}
+
+// Stop ends polling started by [RemoteSource.Start] and waits for it to finish.
+func (p *RemoteSource) Stop() {
+	p.Lock()
+
+	cancel := p.cancel
+	done := p.done
+
+	p.cancel = nil
+	p.done = nil
+
+	p.Unlock()
+
+	if cancel != nil {
+		cancel()
+
+		<-done
+	}
+}
+
+// poll fetches the configuration at the poll interval until ctx is done, then closes done.
+func (p *RemoteSource) poll(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	pollInterval := p.pollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	maxBackoff := p.maxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	delay := pollInterval
+
+	for {
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+
+		_, err := p.Fetch(ctx)
+		if err == nil {
+			delay = pollInterval
+
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.handleError(err)
+
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+// handleError passes err to the error hook, or writes it to the standard logger.
+func (p *RemoteSource) handleError(err error) {
+	if p.errorHook != nil {
+		p.errorHook(err)
+
+		return
+	}
+
+	log.Printf("tcfg: remote configuration %s: %v", p.url, err)
+}
+
+// setProfiles sets the active profiles of the current configuration and of those loaded later.
+func (p *RemoteSource) setProfiles(profiles ...string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.profiles = profiles
+
+	if p.iniData != nil {
+		p.iniData.SetProfiles(profiles...)
+	}
+}
+
+// attach registers the cache epoch of a [ConfData] layering the source, so that changes discard its cached values.
+func (p *RemoteSource) attach(epoch *atomic.Uint64) {
+	if p == nil {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if !slices.Contains(p.epochs, epoch) {
+		p.epochs = append(p.epochs, epoch)
+	}
+}
+
+// update installs iniData as the current configuration, keeping the active profiles of the previous one or those
+// set before the first load. It then discards the caches of the attached [ConfData] values and calls the change
+// callbacks.
+func (p *RemoteSource) update(iniData *IniData, etag string) {
+	p.Lock()
+
+	if p.iniData != nil {
+		p.profiles = p.iniData.Profiles()
+	}
+
+	// iniData is not shared yet, so setting its profiles does not need to discard any cache.
+	iniData.setProfiles(p.profiles...)
+
+	p.iniData = iniData
+	p.etag = etag
+
+	epochs := append([]*atomic.Uint64{}, p.epochs...)
+	onChange := append([]func(iniData *IniData){}, p.onChange...)
+
+	p.Unlock()
+
+	for _, epoch := range epochs {
+		epoch.Add(1)
+	}
+
+	for _, fn := range onChange {
+		fn(iniData)
+	}
+}
+
+// writeCacheFile replaces the cache file with data through a temporary file in the same directory.
+func writeCacheFile(cacheFile string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(cacheFile), filepath.Base(cacheFile)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), cacheFile)
+}
@@ -0,0 +1,278 @@
+package tcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+const remoteBody = `{"Error":0,"Message":"","data":{"configs":[{"key":"HOST","value":"remote.internal"}]}}`
+
+// newRemoteConfData returns a ConfData whose INI data holds HOST and PORT, with remote attached.
+func newRemoteConfData(t *testing.T, remote *RemoteSource) *ConfData {
+	t.Helper()
+
+	configs := []*Config{
+		{Key: "HOST", Value: "local.internal"},
+		{Key: "PORT", Value: "5432"},
+	}
+
+	iniData, err := (&IniMgr{}).ParseConfig(configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confData, err := New(WithIniData(iniData), WithEnvData(NewEnvDataFromMap(nil)), WithRemoteSource(remote))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return confData
+}
+
+func TestRemoteSourceFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(remoteBody))
+	}))
+	defer server.Close()
+
+	remote := NewRemoteSource(server.URL)
+	confData := newRemoteConfData(t, remote)
+
+	changed, err := remote.Fetch(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Fetch() = %v, %v, want true, nil", changed, err)
+	}
+
+	host, err := confData.String("HOST")
+	if err != nil || host != "remote.internal" {
+		t.Errorf("String(HOST) = %q, %v, want %q", host, err, "remote.internal")
+	}
+
+	// PORT is only in the local INI data, which stays available below the remote configuration.
+	port, err := confData.String("PORT")
+	if err != nil || port != "5432" {
+		t.Errorf("String(PORT) = %q, %v, want %q", port, err, "5432")
+	}
+}
+
+func TestRemoteSourceNotModified(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(remoteBody))
+	}))
+	defer server.Close()
+
+	remote := NewRemoteSource(server.URL)
+
+	changed, err := remote.Fetch(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("first Fetch() = %v, %v, want true, nil", changed, err)
+	}
+
+	changed, err = remote.Fetch(context.Background())
+	if err != nil || changed {
+		t.Fatalf("second Fetch() = %v, %v, want false, nil", changed, err)
+	}
+
+	if requests.Load() != 2 {
+		t.Errorf("requests = %d, want 2", requests.Load())
+	}
+
+	if val, ok := remote.IniData().GetString("HOST"); !ok || val != "remote.internal" {
+		t.Errorf("IniData().GetString(HOST) = %q, %v, want %q", val, ok, "remote.internal")
+	}
+}
+
+func TestRemoteSourceErrorEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Error":3,"Message":"unknown application"}`))
+	}))
+	defer server.Close()
+
+	remote := NewRemoteSource(server.URL)
+
+	_, err := remote.Fetch(context.Background())
+	if !errors.Is(err, ErrResponse) {
+		t.Fatalf("Fetch() error = %v, want ErrResponse", err)
+	}
+
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) || responseErr.Code != 3 {
+		t.Errorf("Fetch() error = %#v, want a *ResponseError with code 3", err)
+	}
+
+	if remote.IniData() != nil {
+		t.Error("IniData() != nil after a failed fetch")
+	}
+}
+
+func TestRemoteSourceCacheFallback(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "config.json")
+
+	var failing atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(remoteBody))
+	}))
+	defer server.Close()
+
+	err := NewRemoteSource(server.URL, WithCacheFile(cacheFile)).Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing.Store(true)
+
+	remote := NewRemoteSource(server.URL, WithCacheFile(cacheFile))
+
+	_, err = remote.Fetch(context.Background())
+	if !errors.Is(err, ErrRemoteStatus) {
+		t.Fatalf("Fetch() error = %v, want ErrRemoteStatus", err)
+	}
+
+	err = remote.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v, want the cache file to be used", err)
+	}
+
+	confData := newRemoteConfData(t, remote)
+
+	host, err := confData.String("HOST")
+	if err != nil || host != "remote.internal" {
+		t.Errorf("String(HOST) = %q, %v, want %q", host, err, "remote.internal")
+	}
+}
+
+func TestRemoteSourceCacheWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(remoteBody))
+	}))
+	defer server.Close()
+
+	var hookErrs []error
+
+	cacheFile := filepath.Join(t.TempDir(), "missing", "config.json")
+
+	remote := NewRemoteSource(server.URL, WithCacheFile(cacheFile), WithRemoteErrorHook(func(err error) {
+		hookErrs = append(hookErrs, err)
+	}))
+
+	err := remote.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v, want the failed cache write to be reported to the hook only", err)
+	}
+
+	if len(hookErrs) != 1 {
+		t.Errorf("hook errors = %v, want the failed cache write", hookErrs)
+	}
+
+	if val, ok := remote.IniData().GetString("HOST"); !ok || val != "remote.internal" {
+		t.Errorf("IniData().GetString(HOST) = %q, %v, want %q", val, ok, "remote.internal")
+	}
+}
+
+func TestRemoteSourceInvalidation(t *testing.T) {
+	var version atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"Error":0,"data":{"configs":[{"key":"HOST","value":"v%d"}]}}`, version.Load())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		attach func(remote *RemoteSource) *ConfData
+	}{
+		{
+			name: "option",
+			attach: func(remote *RemoteSource) *ConfData {
+				return newConfData(t, "", nil, WithRemoteSource(remote), WithCache(true))
+			},
+		},
+		{
+			name: "setter",
+			attach: func(remote *RemoteSource) *ConfData {
+				confData := newConfData(t, "", nil, WithCache(true))
+				confData.SetRemoteSource(remote)
+
+				return confData
+			},
+		},
+		{
+			name: "view",
+			attach: func(remote *RemoteSource) *ConfData {
+				return newConfData(t, "", nil, WithRemoteSource(remote), WithCache(true)).WithPrefix("")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version.Store(1)
+
+			remote := NewRemoteSource(server.URL)
+
+			err := remote.Load(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			confData := tt.attach(remote)
+
+			// A ConfData without the source keeps its cache, so the change of the live environment stays unseen.
+			t.Setenv("TCFG_REMOTE_HOST", "a")
+
+			other, err := New(WithCache(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if val := other.DefaultString("TCFG_REMOTE_HOST", ""); val != "a" {
+				t.Fatalf("String(TCFG_REMOTE_HOST) = %q, want %q", val, "a")
+			}
+
+			if val := confData.DefaultString("HOST", ""); val != "v1" {
+				t.Fatalf("String(HOST) = %q, want %q", val, "v1")
+			}
+
+			t.Setenv("TCFG_REMOTE_HOST", "b")
+			version.Store(2)
+
+			_, err = remote.Fetch(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if val := confData.DefaultString("HOST", ""); val != "v2" {
+				t.Errorf("String(HOST) after the change = %q, want %q", val, "v2")
+			}
+
+			if val := other.DefaultString("TCFG_REMOTE_HOST", ""); val != "a" {
+				t.Errorf("String(TCFG_REMOTE_HOST) of a ConfData without the source = %q, want the cached %q", val, "a")
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package tcfg
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+)
+
+// ToConfigs returns the keys of p as [Configs] rows that [IniMgr.ParseConfig] reads back into the same data: keys
+// of the default section as KEY, others as SECTION::KEY, with raw values. Lists are written as JSON arrays and
+// values that would otherwise be trimmed or decoded are quoted. [CHILD : PARENT] relations cannot be expressed in
+// rows, so each section is written with the keys it inherits; profile sections keep their SECTION@PROFILE names.
+func (p *IniData) ToConfigs() *Configs {
+	p.RLock()
+	defer p.RUnlock()
+
+	sections := make([]string, 0, len(p.data))
+
+	for section := range p.data {
+		sections = append(sections, section)
+	}
+
+	sort.Strings(sections)
+
+	configs := &Configs{
+		Configs: make([]*Config, 0),
+	}
+
+	for _, section := range sections {
+		vals := make(map[string]string)
+		listVals := make(map[string][]string)
+
+		// Walk the parents first so that the keys of section override inherited ones.
+		chain := []string{section}
+
+		for parent := p.parents[section]; parent != "" && len(chain) <= len(p.parents); parent = p.parents[parent] {
+			chain = append(chain, parent)
+		}
+
+		for index := len(chain) - 1; index >= 0; index-- {
+			for key, val := range p.data[chain[index]] {
+				vals[key] = val
+
+				delete(listVals, key)
+			}
+
+			for key, val := range p.listData[chain[index]] {
+				listVals[key] = val
+			}
+		}
+
+		keys := make([]string, 0, len(vals))
+
+		for key := range vals {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			// Parsed values never start with '"', nor end with one when they need quotes, so quoteValue succeeds.
+			val, _ := quoteValue(vals[key])
+
+			if listVal, ok := listVals[key]; ok {
+				data, _ := json.Marshal(listVal)
+
+				val = string(data)
+			}
+
+			tmpKey := key
+			if section != DefaultSection {
+				tmpKey = section + "::" + key
+			}
+
+			configs.Configs = append(configs.Configs, &Config{
+				Key:   tmpKey,
+				Value: val,
+			})
+		}
+	}
+
+	return configs
+}
+
+// ToResponse returns the keys listed by [ConfData.Keys] and their fully expanded values as a successful
+// [Response], in the envelope read by [IniMgr.ParseResponse]. Keys are named as in the JSON output of
+// [ConfData.Export], and values are not redacted. A value that fails to expand, or that cannot be quoted (see
+// [ErrUnquotableValue]), is returned as an error.
+func (p *ConfData) ToResponse() (*Response, error) {
+	if p == nil {
+		return nil, ErrNilConfData
+	}
+
+	entries, err := p.exportEntries(&exportOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	configs := entriesToConfigs(entries)
+
+	err = quoteConfigs(configs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Data: configs,
+	}, nil
+}
+
+// ParseResponse decodes a [Response] from r and builds an [IniData] from its configs with [IniMgr.ParseConfig].
+// A non-zero Error code is returned as a [*ResponseError] carrying the message, and a body that is not a valid
+// response, or a successful response without data, as a [*ParseError].
+func (p *IniMgr) ParseResponse(r io.Reader) (*IniData, error) {
+	var resp Response
+
+	err := json.NewDecoder(r).Decode(&resp)
+	if err != nil {
+		return nil, &ParseError{
+			Type: "response",
+
+			Err: err,
+		}
+	}
+
+	if resp.Error != 0 {
+		return nil, &ResponseError{
+			Code:    resp.Error,
+			Message: resp.Message,
+		}
+	}
+
+	if resp.Data == nil {
+		return nil, &ParseError{
+			Type: "response",
+
+			Err: errors.New("the response has no data"),
+		}
+	}
+
+	configs := make([]*Config, 0, len(resp.Data.Configs))
+
+	for _, config := range resp.Data.Configs {
+		if config != nil {
+			configs = append(configs, config)
+		}
+	}
+
+	return p.ParseConfig(configs)
+}
@@ -0,0 +1,161 @@
+package tcfg
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestToConfigsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "plain", src: "NAME = orders\nPORT = 8080\n"},
+		{name: "surrounding spaces", src: `HOST = " db.local "`},
+		{name: "quotes inside", src: "MSG = say \"hi\"\nQ = \"\"a\"\"\n"},
+		{name: "inline array", src: `ARR = [ "x,y", "z" ]`},
+		{name: "quoted inline array", src: `ARR = "[1, 2]"`},
+		{name: "bracket", src: `B = [a"`},
+		{name: "list lines", src: "L[] = a,b\nL[] = c\n"},
+		{name: "placeholders", src: "HOST = db\nURL = http://${HOST}\nLIT = $${HOST}\n"},
+		{name: "sections", src: "[BASE]\nPORT = 1\n[DB : BASE]\nHOST = db\n[DB@PROD]\nHOST = prod\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iniData := parseIni(t, tt.src)
+
+			readBack, err := (&IniMgr{}).ParseConfig(iniData.ToConfigs().Configs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			keys := iniData.Keys()
+			if len(keys) == 0 {
+				t.Fatal("Keys() is empty")
+			}
+
+			for _, key := range keys {
+				wantVal, _ := iniData.GetString(key)
+				gotVal, _ := readBack.GetString(key)
+
+				wantVals, _ := iniData.GetList(key)
+				gotVals, _ := readBack.GetList(key)
+
+				if gotVal != wantVal || !slices.Equal(gotVals, wantVals) {
+					t.Errorf("%s read back as %q, %q, want %q, %q", key, gotVal, gotVals, wantVal, wantVals)
+				}
+			}
+		})
+	}
+}
+
+func TestToResponseRoundTrip(t *testing.T) {
+	src := `
+NAME = orders
+HOST = " db.local "
+URL = http://${HOST}/$${PATH}
+MSG = say "hi"
+ARR = [ "x,y", "z" ]
+[SERVER]
+BANNER = [v1]
+`
+
+	confData := newConfData(t, src, map[string]string{"NAME": "env"})
+
+	resp, err := confData.ToResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iniData, err := (&IniMgr{}).ParseResponse(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := maps.Collect(confData.All())
+	got := maps.Collect(newConfData(t, "", nil, WithIniData(iniData)).All())
+
+	if !maps.Equal(got, want) {
+		t.Errorf("read back %v, want %v", got, want)
+	}
+}
+
+func TestToResponseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		env  map[string]string
+
+		wantErr error
+	}{
+		{name: "broken reference", src: "A = ${MISSING}\n", wantErr: ErrKeyNotFound},
+		{name: "leading quote", src: "A = x\n", env: map[string]string{"A": `"x`}, wantErr: ErrUnquotableValue},
+		{
+			name:    "spaces and trailing quote",
+			src:     "A = x\n",
+			env:     map[string]string{"A": ` x"`},
+			wantErr: ErrUnquotableValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newConfData(t, tt.src, tt.env).ToResponse()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToResponse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+
+		want         map[string]string
+		wantErr      error
+		wantParseErr bool
+	}{
+		{
+			name: "configs",
+			body: `{"error": 0, "data": {"configs": [{"key": "A", "value": "1"}, null, {"key": "DB::HOST", "value": "db"}]}}`,
+			want: map[string]string{"A": "1", "DB::HOST": "db"},
+		},
+		{name: "error code", body: `{"error": 3, "message": "denied"}`, wantErr: ErrResponse},
+		{name: "no data", body: `{"error": 0}`, wantParseErr: true},
+		{name: "invalid body", body: `{`, wantParseErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iniData, err := (&IniMgr{}).ParseResponse(strings.NewReader(tt.body))
+
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) != tt.wantParseErr || (!tt.wantParseErr && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("ParseResponse() error = %v, want %v, *ParseError %v", err, tt.wantErr, tt.wantParseErr)
+			}
+
+			var responseErr *ResponseError
+			if errors.As(err, &responseErr) && responseErr.Message != "denied" {
+				t.Errorf("ParseResponse() error = %v, want a *ResponseError with its message", err)
+			}
+
+			for key, val := range tt.want {
+				if got := iniData.DefaultString(key, ""); got != val {
+					t.Errorf("String(%q) = %q, want %q", key, got, val)
+				}
+			}
+		})
+	}
+}
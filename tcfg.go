@@ -3,12 +3,15 @@ package tcfg
 import (
 	"errors"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/choveylee/terror"
@@ -26,41 +29,32 @@ var (
 	ValStringKeyReplaceReg = regexp.MustCompile(`\$\$\{(.+?)\}|\$\$\[(.+?)\]`)
 )
 
-// DefaultStringsSeparator is the separator used when splitting list values during $[key] expansion.
+// DefaultStringsSeparator is the separator used when splitting list values during $[key] expansion, unless a
+// separator is set with [ConfData.SetListSeparator] or given per placeholder as in $[key|;].
 const (
 	DefaultStringsSeparator = ","
 )
 
-// DefaultAppName is the configuration key that holds the application name for APP_NAME-scoped resolution.
+// DefaultMaxDepth is the default limit on nested ${} and $[] references followed while expanding one value.
 const (
-	DefaultAppName = "APP_NAME"
+	DefaultMaxDepth = 10
 )
 
-// ErrNilConfData is returned when an operation is invoked on a nil *ConfData receiver.
-var ErrNilConfData = errors.New("tcfg: the operation cannot be performed on a nil *ConfData receiver")
-
-var (
-	keyPrefixMutex sync.RWMutex
-
-	defaultKeyPrefix string
+// DefaultMaxExpansion is the default limit on the number of alternatives one value may expand to through $[] lists.
+const (
+	DefaultMaxExpansion = 10000
 )
 
-// GetKeyPrefix returns the global key prefix set by [SetKeyPrefix]. It is safe for concurrent use with [SetKeyPrefix].
-func GetKeyPrefix() string {
-	keyPrefixMutex.RLock()
-	defer keyPrefixMutex.RUnlock()
-
-	return defaultKeyPrefix
-}
-
-// SetKeyPrefix sets the global key prefix used when resolving keys. It is safe for concurrent use with [GetKeyPrefix].
-func SetKeyPrefix(keyPrefix string) {
-	keyPrefixMutex.Lock()
+// DefaultAppName is the configuration key that holds the application name for APP_NAME-scoped resolution.
+const (
+	DefaultAppName = "APP_NAME"
+)
 
-	defaultKeyPrefix = keyPrefix
+// DefaultScopes is the scope chain used until [ConfData.SetScopes] is called.
+var DefaultScopes = []string{DefaultAppName}
 
-	keyPrefixMutex.Unlock()
-}
+// ErrNilConfData is returned when an operation is invoked on a nil *ConfData receiver.
+var ErrNilConfData = errors.New("tcfg: the operation cannot be performed on a nil *ConfData receiver")
 
 // genConfName returns the default configuration file name derived from the current executable path
 // (<basename>_config.ini with the basename lowercased and '-' replaced by '_').
@@ -138,54 +132,78 @@ func analysisConfDir(confDirs []string, confName string) (string, error) {
 	return "", nil
 }
 
-// analysisKey builds uppercased lookup keys with optional prefix and APP_NAME sub-prefix.
-// Keys may use SECTION::KEY. It returns (originalKey, baseKey) for fallback when scoped and base forms differ.
-func analysisKey(key string, prefix string, subPrefix string) (string, string) {
+// analysisKey builds the uppercased lookup keys for key, most specific first. Keys may use SECTION::KEY, in which
+// case the prefix and scopes apply to the KEY part. scopes holds the normalized values of the scope chain: a key
+// starting with the first n of them, joined by '_', yields one fallback per dropped trailing scope, down to the
+// unscoped base key.
+func analysisKey(key string, prefix string, scopes []string) []string {
 	key = strings.ToUpper(key)
 
-	params := strings.Split(key, "::")
-
-	if len(params) == 1 {
-		if strings.HasPrefix(key, prefix) {
-			key = strings.TrimPrefix(key, prefix)
-		}
+	section := ""
 
-		tmpKey := key
-		if strings.HasPrefix(tmpKey, subPrefix) {
-			tmpKey = strings.TrimPrefix(tmpKey, subPrefix)
-		}
+	if tmpSection, tmpKey, ok := cutKey(key); ok {
+		section = tmpSection + "::"
+		key = tmpKey
+	}
 
-		originalKey := prefix + key
-		baseKey := prefix + tmpKey
+	if strings.HasPrefix(key, prefix) {
+		key = strings.TrimPrefix(key, prefix)
+	}
 
-		return originalKey, baseKey
-	} else if len(params) > 1 {
-		section := params[0]
-		secKey := params[1]
+	depth := 0
 
-		if strings.HasPrefix(secKey, prefix) {
-			secKey = strings.TrimPrefix(secKey, prefix)
+	for _, scope := range scopes {
+		if !strings.HasPrefix(key, scope+"_") {
+			break
 		}
 
-		tmpKey := secKey
-		if strings.HasPrefix(tmpKey, subPrefix) {
-			tmpKey = strings.TrimPrefix(tmpKey, subPrefix)
-		}
+		key = strings.TrimPrefix(key, scope+"_")
+		depth++
+	}
+
+	keys := make([]string, 0, depth+1)
 
-		originalKey := fmt.Sprintf("%s::%s%s", section, prefix, secKey)
-		baseKey := fmt.Sprintf("%s::%s%s", section, prefix, tmpKey)
+	for index := depth; index >= 0; index-- {
+		tmpKey := key
+		if index > 0 {
+			tmpKey = strings.Join(scopes[:index], "_") + "_" + key
+		}
 
-		return originalKey, baseKey
+		keys = append(keys, section+prefix+tmpKey)
 	}
 
-	return "", ""
+	return keys
 }
 
 // ConfData combines environment values with parsed INI data. For each key the environment is consulted first.
 type ConfData struct {
 	iniData *IniData
+	// remote is looked up before iniData once it has loaded a configuration.
+	remote *RemoteSource
 
 	envData *EnvData
+
+	strictDefaults bool
+	errorHook      ErrorHook
+
+	maxDepth     int
+	maxExpansion int
+
+	listSeparator string
+
+	templateMode bool
+
+	keyPrefix string
+	// section scopes the keys of a view created by Sub; empty for the root.
+	section string
+	// scopes names the keys forming the scope chain; nil selects [DefaultScopes].
+	scopes []string
+
+	cache *valueCache
+	// epoch is shared by a ConfData and its views and bumped to discard their caches; nil until first used.
+	epoch *atomic.Uint64
+
+	mutex sync.RWMutex
 }
 
 // Configs is a JSON-serializable list of key/value pairs, typically used with [Response].
@@ -260,491 +278,1578 @@ func (p *ConfData) defaultLoad(configName string) error {
 	if err == nil {
 		p.iniData = iniData
 
+		p.clearCache()
+
 		return nil
 	}
 
 	return err
 }
 
-// analysisValue performs ${key} substitution, Cartesian expansion of $[key] list placeholders,
-// and unescaping of $${...} and $$[...] spans. The bool is true if any ${} or $[] substitution ran
-// in the first two phases (the unescape pass may still run when it is false).
-func (p *ConfData) analysisValue(val string) (string, bool, error) {
-	if p == nil {
-		return val, false, ErrNilConfData
+// getIniLayers returns the INI layers, highest priority first: the configuration last loaded by the remote
+// source when one is set and has loaded, then the parsed INI data, which stays available as a fallback.
+func (p *ConfData) getIniLayers() []*IniData {
+	p.mutex.RLock()
+	remote := p.remote
+	iniData := p.iniData
+	p.mutex.RUnlock()
+
+	iniLayers := make([]*IniData, 0, 2)
+
+	if remoteIniData := remote.IniData(); remoteIniData != nil {
+		iniLayers = append(iniLayers, remoteIniData)
 	}
 
-	isMatch := false
+	if iniData != nil {
+		iniLayers = append(iniLayers, iniData)
+	}
 
-	retVal := ""
+	return iniLayers
+}
 
-	// 1. replace all ${key}
-	startIndex := 0
+// analysisValue expands the ${key} and $[key] placeholders in val, the raw value of the last key in chain, and
+// returns the resulting alternatives. A ${key} placeholder is replaced by the expanded value of key, after applying
+// any operator such as ${key:-default} (see [ConfData.resolvePlaceholder]), and a $[key] or $[key|sep] placeholder
+// by each element of its list; when placeholders yield several values, the result is their Cartesian
+// product, with repeated placeholders taking the same value. The product is ordered by placeholder from left to
+// right, the first placeholder varying slowest, and may not exceed the maximum expansion (see
+// [ConfData.SetMaxExpansion]). Escaped $${...} and $$[...] spans are kept literally with one leading $ removed.
+// In template mode val is first rendered as a Go template (see [ConfData.SetTemplateMode]).
+func (p *ConfData) analysisValue(val string, chain []string) ([]string, error) {
+	if p == nil {
+		return nil, ErrNilConfData
+	}
 
-	retMatches := ValStringKeyMatchReg.FindAllStringIndex(val, -1)
+	if strings.Contains(val, TemplateStartStr) && p.isTemplateMode() {
+		var err error
 
-	for _, retMatch := range retMatches {
-		tmpStartIndex := retMatch[0]
-		tmpEndIndex := retMatch[1]
+		val, err = p.renderTemplate(val, chain)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		retVal += val[startIndex:tmpStartIndex]
+	compiled := p.compileValue(val)
 
-		if tmpStartIndex > 0 {
-			if val[tmpStartIndex-1] == '$' {
+	parts := compiled.parts
+	matchKeys := compiled.keys
 
-				retVal += val[tmpStartIndex:tmpEndIndex]
+	matchKeysMap := make(map[string][]string, len(matchKeys))
 
-				startIndex = tmpEndIndex
+	for _, matchKey := range matchKeys {
+		var retVals []string
+		var err error
 
-				continue
+		if matchKey[1] == '{' {
+			retVals, err = p.resolvePlaceholder(matchKey[2:len(matchKey)-1], chain)
+		} else {
+			key, sep := parseListPlaceholder(matchKey[2 : len(matchKey)-1])
+			if sep == "" {
+				sep = p.getListSeparator()
 			}
-		}
 
-		isMatch = true
+			var ok bool
 
-		key := strings.TrimSpace(val[tmpStartIndex+2 : tmpEndIndex-1])
+			retVals, ok, err = p.resolveList(key, sep, chain)
+			if err == nil && !ok {
+				err = newInterpolationError(chain, key, &KeyNotFoundError{Key: key})
+			}
+		}
 
-		realVal, ok, err := p.stringEx(key)
-		if !ok {
-			return val, isMatch, terror.ErrDataNotExist(key)
+		if err != nil {
+			return nil, err
 		}
+
+		matchKeysMap[matchKey] = retVals
+	}
+
+	comboCount := 1
+
+	for _, matchKey := range matchKeys {
+		comboCount *= len(matchKeysMap[matchKey])
+
+		err := p.checkExpansion(chain, comboCount)
 		if err != nil {
-			return val, isMatch, err
+			return nil, err
 		}
+	}
 
-		retVal += realVal
-		startIndex = tmpEndIndex
+	// combos holds one placeholder assignment per result, built so that earlier placeholders vary slowest.
+	combos := []map[string]string{{}}
+
+	for _, matchKey := range matchKeys {
+		tmpCombos := combos
+		combos = make([]map[string]string, 0, len(tmpCombos)*len(matchKeysMap[matchKey]))
+
+		for _, tmpCombo := range tmpCombos {
+			for _, matchVal := range matchKeysMap[matchKey] {
+				combo := make(map[string]string, len(tmpCombo)+1)
+				for tmpKey, tmpVal := range tmpCombo {
+					combo[tmpKey] = tmpVal
+				}
+
+				combo[matchKey] = matchVal
+
+				combos = append(combos, combo)
+			}
+		}
 	}
 
-	retVal += val[startIndex:]
+	rets := make([]string, 0, len(combos))
+
+	for _, combo := range combos {
+		var builder strings.Builder
+
+		for index, part := range parts {
+			if index%2 == 1 {
+				builder.WriteString(combo[part])
+			} else {
+				builder.WriteString(part)
+			}
+		}
 
-	// 2. replace all $[key]
-	val = retVal
-	retVal = ""
+		rets = append(rets, builder.String())
+	}
+
+	return rets, nil
+}
+
+// unescapeValue turns escaped $${...} / $$[...] into literal ${...} / $[...] (strip one leading $; see ValStringKeyReplaceReg).
+func unescapeValue(val string) string {
+	startIndex := 0
 
-	retMatches = ValStringsKeyMatchReg.FindAllStringIndex(val, -1)
+	retVal := ""
 
-	matchKeysMap := make(map[string][]string)
+	retMatches := ValStringKeyReplaceReg.FindAllStringIndex(val, -1)
 
-	// 2.1 search all $[key]
 	for _, retMatch := range retMatches {
 		tmpStartIndex := retMatch[0]
 		tmpEndIndex := retMatch[1]
 
-		if tmpStartIndex > 0 {
-			if val[tmpStartIndex-1] == '$' {
-				continue
-			}
-		}
+		retVal += val[startIndex:tmpStartIndex]
+		retVal += val[tmpStartIndex+1 : tmpEndIndex]
 
-		isMatch = true
+		startIndex = tmpEndIndex
+	}
 
-		matchKey := val[tmpStartIndex:tmpEndIndex]
+	retVal += val[startIndex:]
 
-		_, ok := matchKeysMap[matchKey]
-		if ok {
-			continue
+	return retVal
+}
+
+// checkExpansion returns an [*InterpolationError] wrapping [ErrExpansionLimit] when count alternatives exceed the
+// maximum expansion while expanding the last key in chain.
+func (p *ConfData) checkExpansion(chain []string, count int) error {
+	maxExpansion := p.getMaxExpansion()
+
+	if count <= maxExpansion {
+		return nil
+	}
+
+	return newChainError(chain, fmt.Errorf("%w: more than %d alternatives", ErrExpansionLimit, maxExpansion))
+}
+
+// enterChain returns chain extended with key, or an [*InterpolationError] when key is already being expanded
+// or the chain has reached the maximum depth.
+func (p *ConfData) enterChain(key string, chain []string) ([]string, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+
+	for _, chainKey := range chain {
+		if chainKey == key {
+			return nil, newInterpolationError(chain, key, ErrInterpolationCycle)
 		}
+	}
 
-		key := strings.TrimSpace(val[tmpStartIndex+2 : tmpEndIndex-1])
+	if len(chain) >= p.getMaxDepth() {
+		return nil, newInterpolationError(chain, key, ErrInterpolationDepth)
+	}
 
-		retVals, err := p.Strings(key, DefaultStringsSeparator)
-		if err != nil {
-			return val, isMatch, err
+	return append(chain[:len(chain):len(chain)], key), nil
+}
+
+// resolve returns the alternatives key expands to and whether key exists. chain lists the keys whose expansion
+// led here and is used to detect cycles. key may carry an env:, ini: or self: namespace and is tried in each
+// candidate form returned by [parseReference].
+func (p *ConfData) resolve(key string, chain []string) ([]string, bool, error) {
+	if len(chain) == 0 {
+		return p.resolveRoot(p.qualifyKey(key))
+	}
+
+	return p.resolveCandidates(key, chain)
+}
+
+// resolveRoot resolves a top-level key that is already relative to the root, using the cache when enabled.
+func (p *ConfData) resolveRoot(key string) ([]string, bool, error) {
+	return p.cached(cacheKey("s", key), func() ([]string, bool, error) {
+		return p.resolveCandidates(key, nil)
+	})
+}
+
+// resolveCandidates resolves the first candidate form of key that exists.
+func (p *ConfData) resolveCandidates(key string, chain []string) ([]string, bool, error) {
+	for _, ref := range parseReference(key, chain) {
+		rets, ok, err := p.resolveRef(ref, chain)
+		if ok || err != nil {
+			return rets, ok, err
 		}
+	}
 
-		matchKeysMap[matchKey] = retVals
+	return nil, false, nil
+}
+
+// resolveRef returns the alternatives a single candidate reference expands to and whether it exists.
+func (p *ConfData) resolveRef(ref reference, chain []string) ([]string, bool, error) {
+	val, ok, err := p.stringEx(ref.key, ref.sources)
+	if !ok || err != nil {
+		return nil, ok, err
 	}
 
-	rets := []string{val}
+	chain, err = p.enterChain(ref.chainKey, chain)
+	if err != nil {
+		return nil, true, err
+	}
 
-	for matchKey, matchVals := range matchKeysMap {
-		tmpRets := rets
-		rets = make([]string, 0)
+	rets, err := p.analysisValue(val, chain)
+	if err != nil {
+		return nil, true, err
+	}
 
-		for _, tmpRet := range tmpRets {
-			for _, matchVal := range matchVals {
-				tmpIndex := 0
-				realRet := ""
+	return rets, true, nil
+}
 
-				for {
-					index := strings.Index(tmpRet[tmpIndex:], matchKey)
-					if index == -1 {
-						realRet += tmpRet[tmpIndex:]
-						break
-					}
+// resolveList returns the expanded list elements of key and whether key exists. Elements of native INI lists are
+// expanded one by one; any other value is expanded and then split on sep, or decoded as an inline JSON array.
+// Like [ConfData.resolve], key may carry a namespace and is tried in each candidate form.
+func (p *ConfData) resolveList(key string, sep string, chain []string) ([]string, bool, error) {
+	if len(chain) == 0 {
+		key = p.qualifyKey(key)
 
-					if (tmpIndex+index) > 0 && tmpRet[tmpIndex+index-1] == '$' {
-						realRet += tmpRet[tmpIndex : tmpIndex+index]
-						realRet += matchKey
+		return p.cached(cacheKey("l"+sep, key), func() ([]string, bool, error) {
+			return p.resolveListCandidates(key, sep, nil)
+		})
+	}
 
-						tmpIndex = tmpIndex + index + len(matchKey)
+	return p.resolveListCandidates(key, sep, chain)
+}
 
-						continue
-					}
+// resolveListCandidates resolves the list of the first candidate form of key that exists.
+func (p *ConfData) resolveListCandidates(key string, sep string, chain []string) ([]string, bool, error) {
+	for _, ref := range parseReference(key, chain) {
+		rets, ok, err := p.resolveListRef(ref, sep, chain)
+		if ok || err != nil {
+			return rets, ok, err
+		}
+	}
 
-					realRet += tmpRet[tmpIndex : tmpIndex+index]
-					realRet += matchVal
+	return nil, false, nil
+}
 
-					tmpIndex = tmpIndex + index + len(matchKey)
-				}
+// resolveListRef returns the expanded list elements of a single candidate reference and whether it exists.
+func (p *ConfData) resolveListRef(ref reference, sep string, chain []string) ([]string, bool, error) {
+	for _, lookupKey := range p.lookupKeys(ref.key) {
+		vals, ok, err := p.list(lookupKey, ref.sources)
+		if err != nil {
+			return nil, false, err
+		}
 
-				rets = append(rets, realRet)
+		if !ok {
+			if _, ok, _ := p.string(lookupKey, ref.sources); ok {
+				break
 			}
+
+			continue
 		}
-	}
-	retVal = strings.Join(rets, DefaultStringsSeparator)
 
-	if isMatch {
-		return retVal, isMatch, nil
-	}
+		chain, err := p.enterChain(ref.chainKey, chain)
+		if err != nil {
+			return nil, true, err
+		}
 
-	// Step 3: turn escaped $${...} / $$[...] into literal ${...} / $[...] (strip one leading $; see ValStringKeyReplaceReg).
-	startIndex = 0
+		rets := make([]string, 0, len(vals))
 
-	val = retVal
-	retVal = ""
+		for _, val := range vals {
+			retVals, err := p.analysisValue(val, chain)
+			if err != nil {
+				return nil, true, err
+			}
 
-	retMatches = ValStringKeyReplaceReg.FindAllStringIndex(val, -1)
+			rets = append(rets, retVals...)
 
-	for _, retMatch := range retMatches {
-		tmpStartIndex := retMatch[0]
-		tmpEndIndex := retMatch[1]
+			err = p.checkExpansion(chain, len(rets))
+			if err != nil {
+				return nil, true, err
+			}
+		}
 
-		retVal += val[startIndex:tmpStartIndex]
-		retVal += val[tmpStartIndex+1 : tmpEndIndex]
+		return rets, true, nil
+	}
 
-		startIndex = tmpEndIndex
+	retVals, ok, err := p.resolveRef(ref, chain)
+	if !ok || err != nil {
+		return nil, ok, err
 	}
 
-	retVal += val[startIndex:]
+	if len(retVals) == 1 {
+		return splitInlineList(retVals[0], sep), true, nil
+	}
 
-	return retVal, isMatch, nil
+	return splitInlineList(joinList(retVals, sep), sep), true, nil
 }
 
-// LocalKey returns a key qualified with [GetKeyPrefix] and [DefaultAppName] when APP_NAME is set,
-// after stripping a leading key prefix if present.
+// LocalKey returns a key qualified with the key prefix of p and the values of the scope chain (see
+// [ConfData.SetScopes]), after stripping a leading key prefix if present. With APP_NAME=orders and
+// DEPLOY_ENV=prod in the chain, DB_HOST becomes ORDERS_PROD_DB_HOST. Without any scope value key is returned as is.
 func (p *ConfData) LocalKey(key string) string {
-	appName, ok, err := p.string(DefaultAppName)
-	if err == nil && ok {
-		keyPrefix := GetKeyPrefix()
+	scopeVals := p.scopeValues()
+	if len(scopeVals) == 0 {
+		return key
+	}
 
-		if strings.HasPrefix(key, keyPrefix) {
-			key = strings.TrimPrefix(key, keyPrefix)
-		}
+	keyPrefix := p.KeyPrefix()
+
+	if strings.HasPrefix(key, keyPrefix) {
+		key = strings.TrimPrefix(key, keyPrefix)
+	}
+
+	return fmt.Sprintf("%s%s_%s", keyPrefix, strings.Join(scopeVals, "_"), key)
+}
 
-		appName = strings.ToUpper(strings.Replace(appName, "-", "_", -1))
+// GetBool is like [ConfData.Bool] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetBool(key string) (bool, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return false, ok, err
+	}
 
-		key = fmt.Sprintf("%s%s_%s", keyPrefix, appName, key)
+	ret, err := parseBool(val)
+	if err != nil {
+		err = p.parseError(key, val, "bool", err)
 	}
 
-	return key
+	return ret, true, err
 }
 
 // Bool returns the boolean value associated with key. The underlying string is parsed after [ConfData.String] resolution.
 func (p *ConfData) Bool(key string) (bool, error) {
-	val, err := p.String(key)
+	val, ok, err := p.GetBool(key)
 	if err != nil {
 		return false, err
 	}
 
-	ret, err := parseBool(val)
-	if err != nil {
-		return false, err
+	if !ok {
+		return false, &KeyNotFoundError{Key: key}
 	}
 
-	return ret, nil
+	return val, nil
 }
 
-// DefaultBool returns defaultVal if [ConfData.Bool] would fail or the key is missing.
+// DefaultBool returns defaultVal if the key is missing or [ConfData.GetBool] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultBool(key string, defaultVal bool) bool {
-	val, err := p.Bool(key)
-	if err != nil {
+	val, ok, err := p.GetBool(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVal
 	}
 
 	return val
 }
 
+// GetInt is like [ConfData.Int] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetInt(key string) (int, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := strconv.Atoi(val)
+	if err != nil {
+		err = p.parseError(key, val, "int", err)
+	}
+
+	return ret, true, err
+}
+
 // Int returns the decimal integer associated with key after [ConfData.String] resolution.
 func (p *ConfData) Int(key string) (int, error) {
-	val, err := p.String(key)
+	val, ok, err := p.GetInt(key)
 	if err != nil {
 		return 0, err
 	}
 
-	ret, err := strconv.Atoi(val)
-	if err != nil {
-		return 0, err
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
 	}
 
-	return ret, nil
+	return val, nil
 }
 
-// DefaultInt returns defaultVal if [ConfData.Int] would fail or the key is missing.
+// DefaultInt returns defaultVal if the key is missing or [ConfData.GetInt] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultInt(key string, defaultVal int) int {
-	val, err := p.Int(key)
-	if err != nil {
+	val, ok, err := p.GetInt(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVal
 	}
 
 	return val
 }
 
+// GetInt32 is like [ConfData.Int32] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetInt32(key string) (int32, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := strconv.ParseInt(val, 10, 32)
+	if err != nil {
+		err = p.parseError(key, val, "int32", err)
+	}
+
+	return int32(ret), true, err
+}
+
 // Int32 returns the signed 32-bit integer associated with key after [ConfData.String] resolution.
 func (p *ConfData) Int32(key string) (int32, error) {
-	val, err := p.String(key)
+	val, ok, err := p.GetInt32(key)
 	if err != nil {
 		return 0, err
 	}
 
-	ret, err := strconv.ParseInt(val, 10, 32)
-	if err != nil {
-		return 0, err
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
 	}
 
-	return int32(ret), nil
+	return val, nil
 }
 
-// DefaultInt32 returns defaultVal if [ConfData.Int32] would fail or the key is missing.
+// DefaultInt32 returns defaultVal if the key is missing or [ConfData.GetInt32] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultInt32(key string, defaultVal int32) int32 {
-	val, err := p.Int32(key)
-	if err != nil {
+	val, ok, err := p.GetInt32(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVal
 	}
 
 	return val
 }
 
+// GetInt64 is like [ConfData.Int64] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetInt64(key string) (int64, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		err = p.parseError(key, val, "int64", err)
+	}
+
+	return ret, true, err
+}
+
 // Int64 returns the signed 64-bit integer associated with key after [ConfData.String] resolution.
 func (p *ConfData) Int64(key string) (int64, error) {
-	val, err := p.String(key)
+	val, ok, err := p.GetInt64(key)
 	if err != nil {
 		return 0, err
 	}
 
-	ret, err := strconv.ParseInt(val, 10, 64)
-	if err != nil {
-		return 0, err
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
 	}
 
-	return ret, nil
+	return val, nil
 }
 
-// DefaultInt64 returns defaultVal if [ConfData.Int64] would fail or the key is missing.
+// DefaultInt64 returns defaultVal if the key is missing or [ConfData.GetInt64] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultInt64(key string, defaultVal int64) int64 {
-	val, err := p.Int64(key)
-	if err != nil {
+	val, ok, err := p.GetInt64(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVal
 	}
 
 	return val
 }
 
+// GetFloat32 is like [ConfData.Float32] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetFloat32(key string) (float32, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := strconv.ParseFloat(val, 32)
+	if err != nil {
+		err = p.parseError(key, val, "float32", err)
+	}
+
+	return float32(ret), true, err
+}
+
 // Float32 returns the 32-bit floating-point value associated with key after [ConfData.String] resolution.
 func (p *ConfData) Float32(key string) (float32, error) {
-	val, err := p.String(key)
+	val, ok, err := p.GetFloat32(key)
 	if err != nil {
 		return 0, err
 	}
 
-	ret, err := strconv.ParseFloat(val, 32)
-	if err != nil {
-		return 0, err
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
 	}
 
-	return float32(ret), nil
+	return val, nil
 }
 
-// DefaultFloat32 returns defaultVal if [ConfData.Float32] would fail or the key is missing.
+// DefaultFloat32 returns defaultVal if the key is missing or [ConfData.GetFloat32] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultFloat32(key string, defaultVal float32) float32 {
-	val, err := p.Float32(key)
-	if err != nil {
+	val, ok, err := p.GetFloat32(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVal
 	}
 
 	return val
 }
 
+// GetFloat64 is like [ConfData.Float64] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetFloat64(key string) (float64, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		err = p.parseError(key, val, "float64", err)
+	}
+
+	return ret, true, err
+}
+
 // Float64 returns the 64-bit floating-point value associated with key after [ConfData.String] resolution.
 func (p *ConfData) Float64(key string) (float64, error) {
-	val, err := p.String(key)
+	val, ok, err := p.GetFloat64(key)
 	if err != nil {
 		return 0, err
 	}
 
-	ret, err := strconv.ParseFloat(val, 64)
-	if err != nil {
-		return 0, err
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
 	}
 
-	return ret, nil
+	return val, nil
 }
 
-// DefaultFloat64 returns defaultVal if [ConfData.Float64] would fail or the key is missing.
+// DefaultFloat64 returns defaultVal if the key is missing or [ConfData.GetFloat64] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultFloat64(key string, defaultVal float64) float64 {
-	val, err := p.Float64(key)
-	if err != nil {
+	val, ok, err := p.GetFloat64(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVal
 	}
 
 	return val
 }
 
-// Duration returns the duration associated with key, parsed with [time.ParseDuration] after [ConfData.String] resolution.
-func (p *ConfData) Duration(key string) (time.Duration, error) {
-	val, err := p.String(key)
-	if err != nil {
-		return 0, err
+// GetDuration is like [ConfData.Duration] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetDuration(key string) (time.Duration, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
 	}
 
 	ret, err := time.ParseDuration(val)
 	if err != nil {
-		return 0, err
+		err = p.parseError(key, val, "duration", err)
 	}
 
-	return ret, nil
+	return ret, true, err
 }
 
-// DefaultDuration returns defaultVal if [ConfData.Duration] would fail or the key is missing.
-func (p *ConfData) DefaultDuration(key string, defaultVal time.Duration) time.Duration {
-	val, err := p.Duration(key)
+// Duration returns the duration associated with key, parsed with [time.ParseDuration] after [ConfData.String] resolution.
+func (p *ConfData) Duration(key string) (time.Duration, error) {
+	val, ok, err := p.GetDuration(key)
 	if err != nil {
-		return defaultVal
+		return 0, err
+	}
+
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultDuration returns defaultVal if the key is missing or [ConfData.GetDuration] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultDuration(key string, defaultVal time.Duration) time.Duration {
+	val, ok, err := p.GetDuration(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
 	}
 
 	return val
 }
 
-// String returns the fully expanded value for key: environment and INI resolution, then up to ten rounds
-// of ${} and $[] interpolation.
-func (p *ConfData) String(key string) (string, error) {
-	val, ok, err := p.stringEx(key)
-	if ok {
-		if err == nil {
-			// nested level max 10
-			for i := 0; i < 10; i++ {
-				retVal, isMatch, err := p.analysisValue(val)
-				if err != nil {
-					return val, err
-				}
+// GetUint is like [ConfData.Uint] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetUint(key string) (uint, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
 
-				if !isMatch {
-					return retVal, nil
-				}
+	ret, err := strconv.ParseUint(val, 10, 0)
+	if err != nil {
+		err = p.parseError(key, val, "uint", err)
+	}
 
-				val = retVal
-			}
+	return uint(ret), true, err
+}
 
-			return val, terror.ErrDataNotExist(key)
-		}
+// Uint returns the unsigned integer associated with key after [ConfData.String] resolution.
+func (p *ConfData) Uint(key string) (uint, error) {
+	val, ok, err := p.GetUint(key)
+	if err != nil {
+		return 0, err
+	}
 
-		return val, err
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultUint returns defaultVal if the key is missing or [ConfData.GetUint] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultUint(key string, defaultVal uint) uint {
+	val, ok, err := p.GetUint(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetUint64 is like [ConfData.Uint64] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetUint64(key string) (uint64, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		err = p.parseError(key, val, "uint64", err)
+	}
+
+	return ret, true, err
+}
+
+// Uint64 returns the unsigned 64-bit integer associated with key after [ConfData.String] resolution.
+func (p *ConfData) Uint64(key string) (uint64, error) {
+	val, ok, err := p.GetUint64(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultUint64 returns defaultVal if the key is missing or [ConfData.GetUint64] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultUint64(key string, defaultVal uint64) uint64 {
+	val, ok, err := p.GetUint64(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetBytes is like [ConfData.Bytes] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetBytes(key string) (uint64, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := parseBytes(val)
+	if err != nil {
+		err = p.parseError(key, val, "byte size", err)
+	}
+
+	return ret, true, err
+}
+
+// Bytes returns the byte size associated with key, such as 512MiB or 1.5GB, after [ConfData.String] resolution.
+func (p *ConfData) Bytes(key string) (uint64, error) {
+	val, ok, err := p.GetBytes(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultBytes returns defaultVal if the key is missing or [ConfData.GetBytes] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultBytes(key string, defaultVal uint64) uint64 {
+	val, ok, err := p.GetBytes(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetURL is like [ConfData.URL] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetURL(key string) (*url.URL, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	ret, err := url.Parse(val)
+	if err != nil {
+		err = p.parseError(key, val, "URL", err)
+	}
+
+	return ret, true, err
+}
+
+// URL returns the URL associated with key, parsed with [url.Parse] after [ConfData.String] resolution.
+func (p *ConfData) URL(key string) (*url.URL, error) {
+	val, ok, err := p.GetURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultURL returns defaultVal if the key is missing or [ConfData.GetURL] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultURL(key string, defaultVal *url.URL) *url.URL {
+	val, ok, err := p.GetURL(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetAddr is like [ConfData.Addr] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetAddr(key string) (netip.Addr, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return netip.Addr{}, ok, err
+	}
+
+	ret, err := netip.ParseAddr(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP address", err)
+	}
+
+	return ret, true, err
+}
+
+// Addr returns the IP address associated with key, parsed with [netip.ParseAddr] after [ConfData.String] resolution.
+func (p *ConfData) Addr(key string) (netip.Addr, error) {
+	val, ok, err := p.GetAddr(key)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	if !ok {
+		return netip.Addr{}, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultAddr returns defaultVal if the key is missing or [ConfData.GetAddr] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultAddr(key string, defaultVal netip.Addr) netip.Addr {
+	val, ok, err := p.GetAddr(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetPrefix is like [ConfData.Prefix] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetPrefix(key string) (netip.Prefix, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return netip.Prefix{}, ok, err
+	}
+
+	ret, err := netip.ParsePrefix(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP prefix", err)
+	}
+
+	return ret, true, err
+}
+
+// Prefix returns the IP network prefix associated with key, parsed with [netip.ParsePrefix] after [ConfData.String] resolution.
+func (p *ConfData) Prefix(key string) (netip.Prefix, error) {
+	val, ok, err := p.GetPrefix(key)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	if !ok {
+		return netip.Prefix{}, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultPrefix returns defaultVal if the key is missing or [ConfData.GetPrefix] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultPrefix(key string, defaultVal netip.Prefix) netip.Prefix {
+	val, ok, err := p.GetPrefix(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
 	}
 
+	return val
+}
+
+// GetAddrPort is like [ConfData.AddrPort] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetAddrPort(key string) (netip.AddrPort, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return netip.AddrPort{}, ok, err
+	}
+
+	ret, err := netip.ParseAddrPort(val)
+	if err != nil {
+		err = p.parseError(key, val, "IP address and port", err)
+	}
+
+	return ret, true, err
+}
+
+// AddrPort returns the IP:port pair associated with key, parsed with [netip.ParseAddrPort] after [ConfData.String] resolution.
+func (p *ConfData) AddrPort(key string) (netip.AddrPort, error) {
+	val, ok, err := p.GetAddrPort(key)
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+
+	if !ok {
+		return netip.AddrPort{}, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultAddrPort returns defaultVal if the key is missing or [ConfData.GetAddrPort] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultAddrPort(key string, defaultVal netip.AddrPort) netip.AddrPort {
+	val, ok, err := p.GetAddrPort(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetHostPort is like [ConfData.HostPort] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetHostPort(key string) (string, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+
+	ret, err := parseHostPort(val)
+	if err != nil {
+		err = p.parseError(key, val, "host:port", err)
+	}
+
+	return ret, true, err
+}
+
+// HostPort returns the host:port pair associated with key in canonical form after [ConfData.String] resolution.
+// The port must be numeric.
+func (p *ConfData) HostPort(key string) (string, error) {
+	val, ok, err := p.GetHostPort(key)
 	if err != nil {
 		return "", err
 	}
 
-	return "", terror.ErrDataNotExist(key)
+	if !ok {
+		return "", &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
 }
 
-// stringEx resolves key using APP_NAME-scoped and base key forms, consulting the environment before INI.
-func (p *ConfData) stringEx(key string) (string, bool, error) {
-	appName, ok, err := p.string(DefaultAppName)
+// DefaultHostPort returns defaultVal if the key is missing or [ConfData.GetHostPort] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultHostPort(key string, defaultVal string) string {
+	val, ok, err := p.GetHostPort(key)
 	if !ok || err != nil {
-		appName = ""
-	} else {
-		appName = strings.ToUpper(strings.Replace(appName, "-", "_", -1)) + "_"
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
 	}
 
-	keyPrefix := GetKeyPrefix()
+	return val
+}
+
+// GetTime is like [ConfData.Time] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetTime(key string, layout string) (time.Time, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return time.Time{}, ok, err
+	}
+
+	ret, err := time.Parse(layout, val)
+	if err != nil {
+		err = p.parseError(key, val, "time", err)
+	}
 
-	originalKey, baseKey := analysisKey(key, keyPrefix, appName)
+	return ret, true, err
+}
 
-	val, ok, err := p.string(originalKey)
-	if ok {
-		return val, ok, err
+// Time returns the time associated with key, parsed with [time.Parse] using layout after [ConfData.String] resolution.
+func (p *ConfData) Time(key string, layout string) (time.Time, error) {
+	val, ok, err := p.GetTime(key, layout)
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	if originalKey != baseKey {
-		val, ok, err = p.string(baseKey)
+	if !ok {
+		return time.Time{}, &KeyNotFoundError{Key: key}
 	}
 
-	return val, ok, err
+	return val, nil
+}
+
+// DefaultTime returns defaultVal if the key is missing or [ConfData.GetTime] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultTime(key string, layout string, defaultVal time.Time) time.Time {
+	val, ok, err := p.GetTime(key, layout)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetLocation is like [ConfData.Location] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetLocation(key string) (*time.Location, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	ret, err := time.LoadLocation(val)
+	if err != nil {
+		err = p.parseError(key, val, "time zone", err)
+	}
+
+	return ret, true, err
+}
+
+// Location returns the time zone named by key, loaded with [time.LoadLocation] after [ConfData.String] resolution.
+func (p *ConfData) Location(key string) (*time.Location, error) {
+	val, ok, err := p.GetLocation(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultLocation returns defaultVal if the key is missing or [ConfData.GetLocation] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultLocation(key string, defaultVal *time.Location) *time.Location {
+	val, ok, err := p.GetLocation(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
 }
 
-// string returns the raw value for key from the environment if present, otherwise from INI.
-// A nil receiver returns [ErrNilConfData].
-func (p *ConfData) string(key string) (string, bool, error) {
+// GetRegexp is like [ConfData.Regexp] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetRegexp(key string) (*regexp.Regexp, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	ret, err := regexp.Compile(val)
+	if err != nil {
+		err = p.parseError(key, val, "regular expression", err)
+	}
+
+	return ret, true, err
+}
+
+// Regexp returns the regular expression associated with key, compiled with [regexp.Compile] after [ConfData.String] resolution.
+func (p *ConfData) Regexp(key string) (*regexp.Regexp, error) {
+	val, ok, err := p.GetRegexp(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultRegexp returns defaultVal if the key is missing or [ConfData.GetRegexp] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultRegexp(key string, defaultVal *regexp.Regexp) *regexp.Regexp {
+	val, ok, err := p.GetRegexp(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetFileMode is like [ConfData.FileMode] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetFileMode(key string) (os.FileMode, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	ret, err := parseFileMode(val)
+	if err != nil {
+		err = p.parseError(key, val, "file mode", err)
+	}
+
+	return ret, true, err
+}
+
+// FileMode returns the octal file permission associated with key, such as 0644, after [ConfData.String] resolution.
+func (p *ConfData) FileMode(key string) (os.FileMode, error) {
+	val, ok, err := p.GetFileMode(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return 0, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultFileMode returns defaultVal if the key is missing or [ConfData.GetFileMode] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultFileMode(key string, defaultVal os.FileMode) os.FileMode {
+	val, ok, err := p.GetFileMode(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVal
+	}
+
+	return val
+}
+
+// GetInts is like [ConfData.Ints] but also reports whether the key exists.
+// The error is non-nil when the value cannot be expanded or parsed.
+func (p *ConfData) GetInts(key string, sep string) ([]int, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	ret, err := parseInts(val, sep)
+	if err != nil {
+		err = p.parseError(key, val, "integer list", err)
+	}
+
+	return ret, true, err
+}
+
+// Ints splits the expanded [ConfData.String] value for key using sep and parses each element as a base-10 integer.
+func (p *ConfData) Ints(key string, sep string) ([]int, error) {
+	val, ok, err := p.GetInts(key, sep)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// DefaultInts returns defaultVals if the key is missing or [ConfData.GetInts] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultInts(key string, sep string, defaultVals []int) []int {
+	val, ok, err := p.GetInts(key, sep)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVals
+	}
+
+	return val
+}
+
+// GetString returns the fully expanded value for key, whether the key exists, and an error when expansion fails.
+// When $[] placeholders produce several alternatives they are joined with the list separator, and separators inside
+// an alternative are escaped with a backslash; use [ConfData.Expand] to get the alternatives as a slice.
+func (p *ConfData) GetString(key string) (string, bool, error) {
+	rets, ok, err := p.resolve(key, nil)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+
+	return p.joinValues(rets), true, nil
+}
+
+// joinValues returns the alternatives of an expanded value as one string: a single alternative as is, several
+// joined with the list separator by [joinList].
+func (p *ConfData) joinValues(vals []string) string {
+	if len(vals) == 1 {
+		return vals[0]
+	}
+
+	return joinList(vals, p.getListSeparator())
+}
+
+// Expand returns every alternative the value of key expands to, one per combination of $[] list elements, in the
+// order [ConfData.GetString] joins them. A value without $[] placeholders yields one element.
+func (p *ConfData) Expand(key string) ([]string, error) {
+	rets, ok, err := p.resolve(key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return rets, nil
+}
+
+// String returns the fully expanded value for key: environment and INI resolution, then ${} and $[] interpolation.
+// References are followed up to the maximum depth (see [ConfData.SetMaxDepth]), and a reference cycle yields an
+// [*InterpolationError] wrapping [ErrInterpolationCycle].
+func (p *ConfData) String(key string) (string, error) {
+	val, ok, err := p.GetString(key)
+	if err != nil {
+		return val, err
+	}
+
+	if !ok {
+		return "", &KeyNotFoundError{Key: key}
+	}
+
+	return val, nil
+}
+
+// Lookup implements [Source]. It is equivalent to [ConfData.GetString].
+func (p *ConfData) Lookup(key string) (string, bool, error) {
+	return p.GetString(key)
+}
+
+// lookupList implements listSource with [ConfData.GetList].
+func (p *ConfData) lookupList(key string) ([]string, bool, error) {
+	return p.GetList(key)
+}
+
+// lookupKeys returns the keys tried for key, most specific first: for a key qualified by [ConfData.LocalKey], the
+// fully scoped form, then one form per dropped trailing scope, then the base form.
+func (p *ConfData) lookupKeys(key string) []string {
+	return analysisKey(key, p.KeyPrefix(), p.scopeValues())
+}
+
+// scopeValues returns the normalized values of the scope chain, uppercased with '-' replaced by '_'. Scopes that
+// are unset or empty are skipped.
+func (p *ConfData) scopeValues() []string {
+	scopes := p.Scopes()
+
+	scopeVals := make([]string, 0, len(scopes))
+
+	for _, scope := range scopes {
+		scopeVal, ok, err := p.string(scope, sourceAll)
+		if !ok || err != nil || scopeVal == "" {
+			continue
+		}
+
+		scopeVals = append(scopeVals, strings.ToUpper(strings.Replace(scopeVal, "-", "_", -1)))
+	}
+
+	return scopeVals
+}
+
+// stringEx resolves key using the scoped and base key forms of [ConfData.lookupKeys], consulting the environment before INI.
+// sources restricts the layers consulted.
+func (p *ConfData) stringEx(key string, sources sourceMask) (string, bool, error) {
+	for _, lookupKey := range p.lookupKeys(key) {
+		val, ok, err := p.string(lookupKey, sources)
+		if ok || err != nil {
+			return val, ok, err
+		}
+	}
+
+	return "", false, nil
+}
+
+// string returns the raw value for key from the environment if present, otherwise from INI, limited to the layers
+// in sources. A nil receiver returns [ErrNilConfData].
+func (p *ConfData) string(key string, sources sourceMask) (string, bool, error) {
 	if p == nil {
 		return "", false, ErrNilConfData
 	}
 
-	if p.envData != nil {
+	if p.envData != nil && sources&sourceEnv != 0 {
 		val, ok := p.envData.GetString(key)
 		if ok {
 			return val, ok, nil
 		}
 	}
 
-	if p.iniData != nil {
-		val, ok := p.iniData.GetString(key)
-		if ok {
-			return val, ok, nil
+	if sources&sourceIni != 0 {
+		for _, iniData := range p.getIniLayers() {
+			val, ok := iniData.GetString(key)
+			if ok {
+				return val, ok, nil
+			}
 		}
 	}
 
 	return "", false, nil
 }
 
-// DefaultString returns defaultVal if [ConfData.String] would fail or the key is missing.
+// parseError returns err as a [*ParseError] for key and val, citing the layer that supplied the value of key.
+func (p *ConfData) parseError(key string, val string, typ string, err error) error {
+	parseErr := &ParseError{
+		Key:   key,
+		Value: val,
+		Type:  typ,
+
+		Err: err,
+	}
+
+	if p == nil {
+		return parseErr
+	}
+
+	for _, lookupKey := range p.lookupKeys(key) {
+		if p.envData != nil {
+			if _, ok := p.envData.GetString(lookupKey); ok {
+				parseErr.Source = "env"
+
+				return parseErr
+			}
+		}
+
+		for _, iniData := range p.getIniLayers() {
+			if _, ok := iniData.GetString(lookupKey); ok {
+				parseErr.Source, parseErr.Line = iniData.position(lookupKey)
+
+				return parseErr
+			}
+		}
+	}
+
+	return parseErr
+}
+
+// list returns the raw elements for key when INI holds it as a native list and the environment does not override it,
+// limited to the layers in sources. A nil receiver returns [ErrNilConfData].
+func (p *ConfData) list(key string, sources sourceMask) ([]string, bool, error) {
+	if p == nil {
+		return nil, false, ErrNilConfData
+	}
+
+	if p.envData != nil && sources&sourceEnv != 0 {
+		_, ok := p.envData.GetString(key)
+		if ok {
+			return nil, false, nil
+		}
+	}
+
+	if sources&sourceIni != 0 {
+		// The first layer holding key decides, so a plain value in the remote configuration hides a local list.
+		for _, iniData := range p.getIniLayers() {
+			vals, ok := iniData.getList(key)
+			if ok {
+				return vals, ok, nil
+			}
+
+			if _, ok := iniData.GetString(key); ok {
+				return nil, false, nil
+			}
+		}
+	}
+
+	return nil, false, nil
+}
+
+// DefaultString returns defaultVal if the key is missing or [ConfData.GetString] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultString(key string, defaultVal string) string {
-	val, err := p.String(key)
-	if err != nil {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVal
 	}
 
 	return val
 }
 
-// Strings splits the expanded [ConfData.String] value for key using sep. A single empty field yields an empty slice.
+// GetStrings is like [ConfData.Strings] but also reports whether the key exists.
+func (p *ConfData) GetStrings(key string, sep string) ([]string, bool, error) {
+	val, ok, err := p.GetString(key)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	vals := splitList(val, sep)
+
+	if len(vals) == 1 && vals[0] == "" {
+		return []string{}, true, nil
+	}
+
+	return vals, true, nil
+}
+
+// Strings splits the expanded [ConfData.String] value for key using sep. A separator escaped with a backslash, as
+// [ConfData.String] writes it inside list elements, is kept in the element. A single empty field yields an empty
+// slice.
 func (p *ConfData) Strings(key string, sep string) ([]string, error) {
-	val, err := p.String(key)
+	vals, ok, err := p.GetStrings(key, sep)
 	if err != nil {
 		return nil, err
 	}
 
-	vals := strings.Split(val, sep)
-
-	if len(vals) == 1 && vals[0] == "" {
-		return []string{}, nil
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
 	}
 
 	return vals, nil
 }
 
-// DefaultStrings returns defaultVals if [ConfData.Strings] would fail or the key is missing.
+// DefaultStrings returns defaultVals if the key is missing or [ConfData.GetStrings] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
 func (p *ConfData) DefaultStrings(key string, sep string, defaultVals []string) []string {
-	val, err := p.Strings(key, sep)
+	vals, ok, err := p.GetStrings(key, sep)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVals
+	}
+
+	return vals
+}
+
+// GetList is like [ConfData.List] but also reports whether the key exists.
+func (p *ConfData) GetList(key string) ([]string, bool, error) {
+	return p.resolveList(key, p.getListSeparator(), nil)
+}
+
+// List returns the elements of the list stored for key, each expanded like [ConfData.String]. Unlike [ConfData.Strings],
+// elements of key[] lines and [...] arrays are kept intact even when they contain the separator.
+func (p *ConfData) List(key string) ([]string, error) {
+	vals, ok, err := p.GetList(key)
 	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return vals, nil
+}
+
+// DefaultList returns defaultVals if the key is missing or [ConfData.GetList] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultList(key string, defaultVals []string) []string {
+	vals, ok, err := p.GetList(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
 		return defaultVals
 	}
 
-	return val
+	return vals
 }
 
-// DebugToString returns a human-readable summary of parsed INI data, or a placeholder if p or INI data is nil.
+// GetMap is like [ConfData.Map] but also reports whether the key exists.
+func (p *ConfData) GetMap(key string) (map[string]string, bool, error) {
+	if p == nil {
+		return nil, false, ErrNilConfData
+	}
+
+	iniLayers := p.getIniLayers()
+
+	for _, lookupKey := range p.lookupKeys(p.qualifyKey(key)) {
+		// The sub-keys of every layer are merged; each value is resolved through the layers in priority order.
+		vals := make(map[string]string)
+		found := false
+
+		for _, iniData := range iniLayers {
+			tmpVals, ok := iniData.GetMap(lookupKey)
+			if !ok {
+				continue
+			}
+
+			found = true
+
+			for subKey, val := range tmpVals {
+				vals[subKey] = val
+			}
+		}
+
+		if !found {
+			continue
+		}
+
+		rets := make(map[string]string, len(vals))
+
+		for subKey := range vals {
+			retVals, _, err := p.resolveRoot(lookupKey + MapKeySeparator + subKey)
+			if err != nil {
+				return nil, true, err
+			}
+
+			rets[subKey] = p.joinValues(retVals)
+		}
+
+		return rets, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// Map returns the entries of the map stored for key, written as key.sub = value lines or a {...} object.
+// Sub-keys are uppercased, and each value is resolved with [ConfData.String] so the environment may override it.
+func (p *ConfData) Map(key string) (map[string]string, error) {
+	vals, ok, err := p.GetMap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+
+	return vals, nil
+}
+
+// DefaultMap returns defaultVals if the key is missing or [ConfData.GetMap] fails. With strict defaults enabled,
+// failures other than a missing key are also passed to the error hook.
+func (p *ConfData) DefaultMap(key string, defaultVals map[string]string) map[string]string {
+	vals, ok, err := p.GetMap(key)
+	if !ok || err != nil {
+		p.handleDefaultError(key, ok, err)
+
+		return defaultVals
+	}
+
+	return vals
+}
+
+// DebugToString returns a human-readable summary of the parsed INI data, without the remote configuration, or a
+// placeholder if p or INI data is nil.
 func (p *ConfData) DebugToString() string {
 	if p == nil {
 		return "ini config data: <nil>."
 	}
 
-	if p.iniData == nil {
+	p.mutex.RLock()
+	iniData := p.iniData
+	p.mutex.RUnlock()
+
+	if iniData == nil {
 		return "ini config data: <nil>."
 	}
 
-	strIniData, _ := p.iniData.toString()
+	strIniData, _ := iniData.toString()
 
 	return fmt.Sprintf("ini config data: %s.",
 		strIniData)
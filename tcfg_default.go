@@ -12,35 +12,132 @@ func init() {
 	}
 }
 
+// Default returns the ConfData used by the package-level accessors.
+func Default() *ConfData {
+	return defaultConfData
+}
+
 // Package-level variables are bound to defaultConfData and mirror the corresponding (*ConfData)
 // methods loaded during init.
 var LocalKey = defaultConfData.LocalKey
 
+// GetKeyPrefix returns the key prefix of the default instance (see [ConfData.KeyPrefix]).
+var GetKeyPrefix = defaultConfData.KeyPrefix
+
+// SetKeyPrefix sets the key prefix of the default instance (see [ConfData.SetKeyPrefix]).
+var SetKeyPrefix = defaultConfData.SetKeyPrefix
+
+var SetStrictDefaults = defaultConfData.SetStrictDefaults
+var SetErrorHook = defaultConfData.SetErrorHook
+
+var SetMaxDepth = defaultConfData.SetMaxDepth
+var SetMaxExpansion = defaultConfData.SetMaxExpansion
+var SetListSeparator = defaultConfData.SetListSeparator
+var SetTemplateMode = defaultConfData.SetTemplateMode
+var SetCache = defaultConfData.SetCache
+var SetEnvMapper = defaultConfData.SetEnvMapper
+var SetScopes = defaultConfData.SetScopes
+var SetProfiles = defaultConfData.SetProfiles
+var SetRemoteSource = defaultConfData.SetRemoteSource
+var Refresh = defaultConfData.Refresh
+
+var GetBool = defaultConfData.GetBool
 var Bool = defaultConfData.Bool
 var DefaultBool = defaultConfData.DefaultBool
 
+var GetInt = defaultConfData.GetInt
 var Int = defaultConfData.Int
 var DefaultInt = defaultConfData.DefaultInt
 
+var GetInt32 = defaultConfData.GetInt32
 var Int32 = defaultConfData.Int32
 var DefaultInt32 = defaultConfData.DefaultInt32
 
+var GetInt64 = defaultConfData.GetInt64
 var Int64 = defaultConfData.Int64
 var DefaultInt64 = defaultConfData.DefaultInt64
 
+var GetFloat32 = defaultConfData.GetFloat32
 var Float32 = defaultConfData.Float32
 var DefaultFloat32 = defaultConfData.DefaultFloat32
 
+var GetFloat64 = defaultConfData.GetFloat64
 var Float64 = defaultConfData.Float64
 var DefaultFloat64 = defaultConfData.DefaultFloat64
 
+var GetDuration = defaultConfData.GetDuration
 var Duration = defaultConfData.Duration
 var DefaultDuration = defaultConfData.DefaultDuration
 
+var GetUint = defaultConfData.GetUint
+var Uint = defaultConfData.Uint
+var DefaultUint = defaultConfData.DefaultUint
+
+var GetUint64 = defaultConfData.GetUint64
+var Uint64 = defaultConfData.Uint64
+var DefaultUint64 = defaultConfData.DefaultUint64
+
+var GetBytes = defaultConfData.GetBytes
+var Bytes = defaultConfData.Bytes
+var DefaultBytes = defaultConfData.DefaultBytes
+
+var GetURL = defaultConfData.GetURL
+var URL = defaultConfData.URL
+var DefaultURL = defaultConfData.DefaultURL
+
+var GetAddr = defaultConfData.GetAddr
+var Addr = defaultConfData.Addr
+var DefaultAddr = defaultConfData.DefaultAddr
+
+var GetPrefix = defaultConfData.GetPrefix
+var Prefix = defaultConfData.Prefix
+var DefaultPrefix = defaultConfData.DefaultPrefix
+
+var GetAddrPort = defaultConfData.GetAddrPort
+var AddrPort = defaultConfData.AddrPort
+var DefaultAddrPort = defaultConfData.DefaultAddrPort
+
+var GetHostPort = defaultConfData.GetHostPort
+var HostPort = defaultConfData.HostPort
+var DefaultHostPort = defaultConfData.DefaultHostPort
+
+var GetTime = defaultConfData.GetTime
+var Time = defaultConfData.Time
+var DefaultTime = defaultConfData.DefaultTime
+
+var GetLocation = defaultConfData.GetLocation
+var Location = defaultConfData.Location
+var DefaultLocation = defaultConfData.DefaultLocation
+
+var GetRegexp = defaultConfData.GetRegexp
+var Regexp = defaultConfData.Regexp
+var DefaultRegexp = defaultConfData.DefaultRegexp
+
+var GetFileMode = defaultConfData.GetFileMode
+var FileMode = defaultConfData.FileMode
+var DefaultFileMode = defaultConfData.DefaultFileMode
+
+var GetInts = defaultConfData.GetInts
+var Ints = defaultConfData.Ints
+var DefaultInts = defaultConfData.DefaultInts
+
+var GetString = defaultConfData.GetString
 var String = defaultConfData.String
 var DefaultString = defaultConfData.DefaultString
 
+var GetStrings = defaultConfData.GetStrings
 var Strings = defaultConfData.Strings
 var DefaultStrings = defaultConfData.DefaultStrings
 
+var GetList = defaultConfData.GetList
+var List = defaultConfData.List
+var DefaultList = defaultConfData.DefaultList
+
+var Expand = defaultConfData.Expand
+var Export = defaultConfData.Export
+
+var GetMap = defaultConfData.GetMap
+var Map = defaultConfData.Map
+var DefaultMap = defaultConfData.DefaultMap
+
 var DebugToString = defaultConfData.DebugToString
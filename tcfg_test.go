@@ -0,0 +1,208 @@
+package tcfg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newConfData returns a ConfData over the INI text src and a snapshot of the variables in env.
+func newConfData(t *testing.T, src string, env map[string]string, opts ...Option) *ConfData {
+	t.Helper()
+
+	opts = append([]Option{WithIniData(parseIni(t, src)), WithEnvData(NewEnvDataFromMap(env))}, opts...)
+
+	confData, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return confData
+}
+
+func TestConfDataList(t *testing.T) {
+	confData := newConfData(t, "L[] = a,b\nL[] = c\nARR = [ \"x,y\", \"z\" ]\n", nil)
+
+	tests := []struct {
+		key string
+
+		want []string
+	}{
+		{key: "L", want: []string{"a,b", "c"}},
+		{key: "ARR", want: []string{"x,y", "z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			vals, err := confData.List(tt.key)
+			if err != nil || !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("List(%q) = %q, %v, want %q", tt.key, vals, err, tt.want)
+			}
+
+			vals, err = confData.Strings(tt.key, DefaultStringsSeparator)
+			if err != nil || !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("Strings(%q) = %q, %v, want %q", tt.key, vals, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfDataGetInt(t *testing.T) {
+	confData := newConfData(t, "PORT = 8080\nBAD = 80a\n", nil)
+
+	tests := []struct {
+		key string
+
+		want    int
+		wantOk  bool
+		wantErr bool
+	}{
+		{key: "PORT", want: 8080, wantOk: true},
+		{key: "BAD", wantOk: true, wantErr: true},
+		{key: "MISSING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, ok, err := confData.GetInt(tt.key)
+			if val != tt.want || ok != tt.wantOk || (err != nil) != tt.wantErr {
+				t.Errorf("GetInt(%q) = %d, %v, %v, want %d, %v, error %v", tt.key, val, ok, err, tt.want, tt.wantOk,
+					tt.wantErr)
+			}
+
+			_, err = confData.Int(tt.key)
+			if (err != nil) != (tt.wantErr || !tt.wantOk) {
+				t.Errorf("Int(%q) error = %v", tt.key, err)
+			}
+		})
+	}
+}
+
+func TestInterpolationCycle(t *testing.T) {
+	confData := newConfData(t, "A = ${B}\nB = x${C}\nC = ${A}\nS = ${S}\nL = $[L]\nOK = ${B2}\nB2 = v\n", nil)
+
+	tests := []struct {
+		key string
+
+		wantChain string
+	}{
+		{key: "A", wantChain: "A B C A"},
+		{key: "S", wantChain: "S S"},
+		{key: "L", wantChain: "L L"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			_, err := confData.String(tt.key)
+			if !errors.Is(err, ErrInterpolationCycle) {
+				t.Fatalf("String(%q) error = %v, want ErrInterpolationCycle", tt.key, err)
+			}
+
+			var interpolationErr *InterpolationError
+			if !errors.As(err, &interpolationErr) || strings.Join(interpolationErr.Chain, " ") != tt.wantChain {
+				t.Errorf("String(%q) error = %v, want the chain %s", tt.key, err, tt.wantChain)
+			}
+		})
+	}
+
+	if val, err := confData.String("OK"); err != nil || val != "v" {
+		t.Errorf("String(OK) = %q, %v, want %q", val, err, "v")
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	src := "D1 = ${D2}\nD2 = ${D3}\nD3 = ${D4}\nD4 = v\n"
+
+	tests := []struct {
+		name     string
+		maxDepth int
+
+		wantErr error
+	}{
+		{name: "default", maxDepth: 0},
+		{name: "deep enough", maxDepth: 4},
+		{name: "too shallow", maxDepth: 2, wantErr: ErrInterpolationDepth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, nil, WithMaxDepth(tt.maxDepth))
+
+			val, err := confData.String("D1")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && val != "v") {
+				t.Errorf("String(D1) = %q, %v, want %q, %v", val, err, "v", tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListSeparator(t *testing.T) {
+	src := "HOSTS = a,b\nSEMI = x;y\nT = [ \"p,q\", \"r\" ]\nV = h-$[HOSTS]\nW = h-$[SEMI|;]\nX = $[T]\n"
+
+	tests := []struct {
+		name string
+		sep  string
+		key  string
+
+		want       string
+		wantExpand []string
+	}{
+		{name: "default", key: "V", want: "h-a,h-b", wantExpand: []string{"h-a", "h-b"}},
+		{name: "per placeholder", key: "W", want: "h-x,h-y", wantExpand: []string{"h-x", "h-y"}},
+		{name: "escaped", key: "X", want: `p\,q,r`, wantExpand: []string{"p,q", "r"}},
+		{name: "instance", sep: ";", key: "V", want: "h-a,b", wantExpand: []string{"h-a,b"}},
+		{name: "instance per placeholder", sep: ";", key: "W", want: "h-x;h-y", wantExpand: []string{"h-x", "h-y"}},
+		{name: "instance escaped", sep: ";", key: "X", want: "p,q;r", wantExpand: []string{"p,q", "r"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, nil, WithListSeparator(tt.sep))
+
+			val, err := confData.String(tt.key)
+			if err != nil || val != tt.want {
+				t.Errorf("String(%q) = %q, %v, want %q", tt.key, val, err, tt.want)
+			}
+
+			vals, err := confData.Expand(tt.key)
+			if err != nil || !reflect.DeepEqual(vals, tt.wantExpand) {
+				t.Errorf("Expand(%q) = %q, %v, want %q", tt.key, vals, err, tt.wantExpand)
+			}
+
+			vals, err = confData.Strings(tt.key, confData.getListSeparator())
+			if err != nil || !reflect.DeepEqual(vals, tt.wantExpand) {
+				t.Errorf("Strings(%q) = %q, %v, want %q", tt.key, vals, err, tt.wantExpand)
+			}
+		})
+	}
+}
+
+func TestExpansion(t *testing.T) {
+	src := "A = 1,2\nB = x,y\nC = p,q,r\nV = $[A]$[B]\nW = $[B]-$[A]\nX = $[A]$[B]$[C]\n"
+
+	tests := []struct {
+		name         string
+		maxExpansion int
+		key          string
+
+		want    []string
+		wantErr error
+	}{
+		{name: "first varies slowest", key: "V", want: []string{"1x", "1y", "2x", "2y"}},
+		{name: "placeholder order", key: "W", want: []string{"x-1", "x-2", "y-1", "y-2"}},
+		{name: "at the limit", maxExpansion: 4, key: "V", want: []string{"1x", "1y", "2x", "2y"}},
+		{name: "over the limit", maxExpansion: 10, key: "X", wantErr: ErrExpansionLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src, nil, WithMaxExpansion(tt.maxExpansion))
+
+			vals, err := confData.Expand(tt.key)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(vals, tt.want) {
+				t.Errorf("Expand(%q) = %q, %v, want %q, %v", tt.key, vals, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package tcfg
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateStartStr marks a value rendered with text/template when template mode is enabled.
+const TemplateStartStr = "{{"
+
+// WithTemplateMode enables or disables text/template rendering of values (see [ConfData.SetTemplateMode]).
+func WithTemplateMode(enabled bool) Option {
+	return func(p *ConfData) {
+		p.templateMode = enabled
+	}
+}
+
+// SetTemplateMode enables or disables text/template rendering of values. When enabled, a value containing {{ is
+// rendered as a Go template before ${} and $[] expansion. The template data is the resolved configuration: keys of
+// the default section and environment variables at the top level, as in {{ .HOST }}, and each INI section as a
+// nested map, as in {{ .DB.HOST }}, with dotted sections nested further, as in {{ .SERVER.HTTP.PORT }}. Values in
+// the data are fully expanded with the environment overlay applied.
+//
+// Templates may call default, as in {{ .DB.PORT | default 5432 }}, and every function registered with
+// [RegisterFunc], as in {{ upper "APP_NAME" }}.
+func (p *ConfData) SetTemplateMode(enabled bool) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.templateMode = enabled
+
+	p.mutex.Unlock()
+
+	p.clearCache()
+}
+
+// isTemplateMode reports whether template rendering is enabled.
+func (p *ConfData) isTemplateMode() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.templateMode
+}
+
+// renderTemplate renders val, the raw value of the last key in chain, as a Go template.
+func (p *ConfData) renderTemplate(val string, chain []string) (string, error) {
+	compiled, err := p.compileTemplate(val, chain)
+	if err != nil {
+		return "", newChainError(chain, err)
+	}
+
+	data, err := p.templateData(compiled.refs, chain)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	err = compiled.tmpl.Execute(&builder, data)
+	if err != nil {
+		return "", newChainError(chain, err)
+	}
+
+	return builder.String(), nil
+}
+
+// templateFuncs returns default and the registered functions bound to chain.
+func (p *ConfData) templateFuncs(chain []string) template.FuncMap {
+	funcMap := template.FuncMap{}
+
+	funcMutex.RLock()
+
+	for name, fn := range funcs {
+		funcMap[name] = func(args ...string) (string, error) {
+			return fn(&FuncContext{conf: p, chain: chain}, args)
+		}
+	}
+
+	funcMutex.RUnlock()
+
+	funcMap["default"] = templateDefault
+
+	return funcMap
+}
+
+// templateDefault returns val, or defaultVal when val is missing, nil or empty.
+func templateDefault(defaultVal interface{}, vals ...interface{}) interface{} {
+	if len(vals) == 0 || vals[0] == nil {
+		return defaultVal
+	}
+
+	ref := reflect.ValueOf(vals[0])
+
+	switch ref.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if ref.Len() == 0 {
+			return defaultVal
+		}
+	}
+
+	return vals[0]
+}
+
+// templateData returns the data for a template making the field references refs. Only the keys and sections the
+// template refers to are resolved (see [ConfData.templateRef]).
+func (p *ConfData) templateData(refs [][]string, chain []string) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+
+	sections := p.templateSections()
+
+	for _, ref := range refs {
+		err := p.templateRef(data, sections, ref, chain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// templateRef adds the values the field reference ref needs to data. The longest prefix of ref naming a section
+// selects it, with dotted sections nested, so .SERVER.HTTP.PORT reads PORT of [SERVER.HTTP] even when there is no
+// [SERVER]. The identifier after the section selects one key; without one, every key of the section and of the
+// sections below it is resolved. A reference that names no section reads a key of the default section, as .HOST
+// resolves HOST.
+func (p *ConfData) templateRef(data map[string]interface{}, sections []string, ref []string, chain []string) error {
+	for index := len(ref); index >= 1; index-- {
+		name := strings.ToUpper(strings.Join(ref[:index], SectionSeparator))
+
+		isSection := slices.Contains(sections, name)
+		if !isSection && !hasSubsection(sections, name) {
+			continue
+		}
+
+		sectionData := templateMap(data, ref[:index])
+		if sectionData == nil {
+			return nil
+		}
+
+		if index == len(ref) {
+			return p.templateSubtree(sectionData, sections, name, chain)
+		}
+
+		if !isSection {
+			return nil
+		}
+
+		if _, ok := sectionData[ref[index]]; ok {
+			return nil
+		}
+
+		val, ok, err := p.templateValue(name+"::"+ref[index], chain)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			sectionData[ref[index]] = val
+		}
+
+		return nil
+	}
+
+	if _, ok := data[ref[0]]; ok {
+		return nil
+	}
+
+	val, ok, err := p.templateValue(ref[0], chain)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		data[ref[0]] = val
+	}
+
+	return nil
+}
+
+// templateSubtree resolves every key of section name and of the sections below it into sectionData, nesting the
+// sections below it by name. Keys whose expansion leads back to the value being rendered are left out.
+func (p *ConfData) templateSubtree(sectionData map[string]interface{}, sections []string, name string,
+	chain []string) error {
+	for _, section := range sections {
+		subName, ok := strings.CutPrefix(section, name)
+		if !ok || (subName != "" && !strings.HasPrefix(subName, SectionSeparator)) {
+			continue
+		}
+
+		tmpData := sectionData
+		if subName != "" {
+			tmpData = templateMap(sectionData, strings.Split(strings.TrimPrefix(subName, SectionSeparator),
+				SectionSeparator))
+			if tmpData == nil {
+				continue
+			}
+		}
+
+		keys, _ := p.sectionKeys(section)
+
+		for _, key := range keys {
+			if _, ok := tmpData[key]; ok {
+				continue
+			}
+
+			val, ok, err := p.templateValue(section+"::"+key, chain)
+			if errors.Is(err, ErrInterpolationCycle) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				tmpData[key] = val
+			}
+		}
+	}
+
+	return nil
+}
+
+// templateMap returns the map nested in data under path, creating the missing levels. It returns nil when a
+// value other than a map is already stored along path.
+func templateMap(data map[string]interface{}, path []string) map[string]interface{} {
+	for _, name := range path {
+		val, ok := data[name]
+		if !ok {
+			subData := make(map[string]interface{})
+			data[name] = subData
+			data = subData
+
+			continue
+		}
+
+		subData, ok := val.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		data = subData
+	}
+
+	return data
+}
+
+// templateSections returns the uppercased names of the INI sections of every layer, in sorted order, without the
+// default section and profile overlays.
+func (p *ConfData) templateSections() []string {
+	sections := make([]string, 0)
+
+	for _, iniData := range p.getIniLayers() {
+		for _, section := range iniData.Sections() {
+			if section == DefaultSection || strings.Contains(section, ProfileSeparator) {
+				continue
+			}
+
+			if !slices.Contains(sections, section) {
+				sections = append(sections, section)
+			}
+		}
+	}
+
+	sort.Strings(sections)
+
+	return sections
+}
+
+// hasSubsection reports whether sections holds a section below name, as SERVER.HTTP is below SERVER.
+func hasSubsection(sections []string, name string) bool {
+	for _, section := range sections {
+		if strings.HasPrefix(section, name+SectionSeparator) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// templateValue returns the expanded value of key as one string.
+func (p *ConfData) templateValue(key string, chain []string) (string, bool, error) {
+	vals, ok, err := p.resolve(key, chain)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+
+	return p.joinValues(vals), true, nil
+}
+
+// sectionKeys returns the keys of the INI section named name in every INI layer, matched case-insensitively,
+// including inherited keys. The default section is not returned, since its keys sit at the top level of the
+// template data.
+func (p *ConfData) sectionKeys(name string) ([]string, bool) {
+	if strings.EqualFold(name, DefaultSection) {
+		return nil, false
+	}
+
+	found := false
+
+	keysMap := make(map[string]bool)
+
+	for _, iniData := range p.getIniLayers() {
+		tmpKeys, ok := iniData.sectionKeys(strings.ToUpper(name))
+		if !ok {
+			continue
+		}
+
+		found = true
+
+		for _, key := range tmpKeys {
+			keysMap[key] = true
+		}
+	}
+
+	if !found {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(keysMap))
+
+	for key := range keysMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys, true
+}
+
+// walkTemplateNode calls fn with the identifiers of every field reference in node, relative to the root of the
+// template data, such as [DB HOST] for .DB.HOST or $.DB.HOST. dot holds the path of the value . refers to, so
+// .HOST inside {{ with .DB }} yields [DB HOST]; it is nil where . refers to a value other than a path of the data,
+// as inside {{ range }}, and field references there are skipped. The root is an empty, non-nil path.
+func walkTemplateNode(node parse.Node, dot []string, fn func(idents []string)) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+
+		for _, subNode := range node.Nodes {
+			walkTemplateNode(subNode, dot, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplateNode(node.Pipe, dot, fn)
+	case *parse.IfNode:
+		walkTemplateNode(node.Pipe, dot, fn)
+		walkTemplateNode(node.List, dot, fn)
+		walkTemplateNode(node.ElseList, dot, fn)
+	case *parse.RangeNode:
+		walkTemplateNode(node.Pipe, dot, fn)
+		walkTemplateNode(node.List, nil, fn)
+		walkTemplateNode(node.ElseList, dot, fn)
+	case *parse.WithNode:
+		walkTemplateNode(node.Pipe, dot, fn)
+		walkTemplateNode(node.List, templatePipeDot(node.Pipe, dot), fn)
+		walkTemplateNode(node.ElseList, dot, fn)
+	case *parse.TemplateNode:
+		walkTemplateNode(node.Pipe, dot, fn)
+	case *parse.PipeNode:
+		if node == nil {
+			return
+		}
+
+		for _, cmd := range node.Cmds {
+			walkTemplateNode(cmd, dot, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range node.Args {
+			walkTemplateNode(arg, dot, fn)
+		}
+	case *parse.ChainNode:
+		walkTemplateNode(node.Node, dot, fn)
+	case *parse.FieldNode:
+		if dot != nil {
+			fn(append(dot[:len(dot):len(dot)], node.Ident...))
+		}
+	case *parse.VariableNode:
+		if len(node.Ident) > 1 && node.Ident[0] == "$" {
+			fn(node.Ident[1:])
+		}
+	}
+}
+
+// templatePipeDot returns the path of the value that pipe, the pipeline of a with action, sets . to: the path of
+// a single field reference such as .DB or $.DB, or nil for any other pipeline.
+func templatePipeDot(pipe *parse.PipeNode, dot []string) []string {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+
+	switch node := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		if dot != nil {
+			return append(dot[:len(dot):len(dot)], node.Ident...)
+		}
+	case *parse.VariableNode:
+		if len(node.Ident) > 1 && node.Ident[0] == "$" {
+			return node.Ident[1:]
+		}
+	case *parse.DotNode:
+		return dot
+	}
+
+	return nil
+}
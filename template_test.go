@@ -0,0 +1,81 @@
+package tcfg
+
+import (
+	"testing"
+)
+
+func TestTemplateMode(t *testing.T) {
+	src := `
+HOST = localhost
+NAME = orders
+HOSTS[] = a
+HOSTS[] = b
+LABEL = ${MISSING}
+
+[DB]
+HOST = db.internal
+LABEL = primary
+PORT = 5432
+URL = ${HOST}:${PORT}
+
+[SERVER.HTTP]
+PORT = 8080
+
+[SERVER.HTTP.TLS]
+CERT = cert.pem
+`
+
+	tests := []struct {
+		name string
+		val  string
+		env  map[string]string
+
+		want string
+	}{
+		{name: "top level", val: "{{ .HOST }}", want: "localhost"},
+		{name: "section key", val: "{{ .DB.HOST }}:{{ .DB.PORT }}", want: "db.internal:5432"},
+		{name: "expanded value", val: "{{ .DB.URL }}", want: "db.internal:5432"},
+		{
+			name: "environment overlay",
+			val:  "{{ .DB.PORT }}",
+			env:  map[string]string{"PORT_DB": "6543"},
+			want: "6543",
+		},
+		{name: "dotted section", val: "{{ .SERVER.HTTP.PORT }}", want: "8080"},
+		{name: "nested dotted section", val: "{{ .SERVER.HTTP.TLS.CERT }}", want: "cert.pem"},
+		{name: "with", val: "{{ with .DB }}{{ .HOST }}:{{ .PORT }}{{ end }}", want: "db.internal:5432"},
+		{name: "with shadowed key", val: "{{ with .DB }}{{ .LABEL }}{{ end }}", want: "primary"},
+		{name: "nested with", val: "{{ with .SERVER }}{{ with .HTTP }}{{ .PORT }}{{ end }}{{ end }}", want: "8080"},
+		{
+			name: "with root variable",
+			val:  "{{ with .DB }}{{ .HOST }}@{{ $.HOST }}{{ end }}",
+			want: "db.internal@localhost",
+		},
+		{name: "with else", val: "{{ with .MISSING }}x{{ else }}{{ .NAME }}{{ end }}", want: "orders"},
+		{name: "range", val: "{{ range $k, $v := .DB }}{{ $k }} {{ end }}", want: "HOST LABEL PORT URL "},
+		{name: "range dot", val: `{{ range $k, $v := .SERVER.HTTP.TLS }}{{ $k }}={{ . }} {{ end }}`, want: "CERT=cert.pem "},
+		{name: "default", val: "{{ .DB.USER | default \"admin\" }}", want: "admin"},
+		{name: "function", val: `{{ upper "NAME" }}`, want: "ORDERS"},
+		{name: "list", val: `{{ join "HOSTS" ";" }}`, want: "a;b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confData := newConfData(t, src+"\n[T]\nV = "+tt.val+"\n", tt.env, WithTemplateMode(true))
+
+			val, err := confData.String("T::V")
+			if err != nil || val != tt.want {
+				t.Errorf("String(T::V) = %q, %v, want %q", val, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateModeDisabled(t *testing.T) {
+	confData := newConfData(t, "HOST = localhost\nV = {{ .HOST }}\n", nil)
+
+	val, err := confData.String("V")
+	if err != nil || val != "{{ .HOST }}" {
+		t.Errorf("String(V) = %q, %v, want the template text", val, err)
+	}
+}